@@ -1,6 +1,7 @@
 package querybuilder
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
@@ -11,6 +12,30 @@ type Assigner struct {
 	Value interface{} `json:"value"`
 }
 
+type assignerJSON struct {
+	Field string      `json:"field"`
+	Value interface{} `json:"value"`
+	Type  string      `json:"type,omitempty"`
+}
+
+func (a *Assigner) MarshalJSON() ([]byte, error) {
+	v, typ := TypedValue(a.Value)
+	return marshalJSON(&assignerJSON{Field: a.Field, Value: v, Type: typ})
+}
+
+func (a *Assigner) UnmarshalJSON(b []byte) error {
+	var src assignerJSON
+	if err := json.Unmarshal(b, &src); err != nil {
+		return err
+	}
+	v, err := UntypedValue(src.Value, src.Type)
+	if err != nil {
+		return err
+	}
+	a.Field, a.Value = src.Field, v
+	return nil
+}
+
 func (a *Assigner) Do(entity interface{}) error {
 	e := reflect.ValueOf(entity)
 	if e.Type().Kind() == reflect.Ptr {
@@ -20,7 +45,7 @@ func (a *Assigner) Do(entity interface{}) error {
 	switch e.Type().Kind() {
 	case reflect.Struct:
 		err := ReflectWalkIn(&e, a.Field, ".", func(f *reflect.Value) error {
-			f.Set(v.Convert(f.Type()))
+			f.Set(convertValue(v, f.Type()))
 			return nil
 		})
 		if err != nil {
@@ -61,6 +86,14 @@ func (s Assigners) AssignAll(entities interface{}) error {
 	return nil
 }
 
+// convertValue converts v to t. nil is converted to the zero value of t.
+func convertValue(v reflect.Value, t reflect.Type) reflect.Value {
+	if !v.IsValid() {
+		return reflect.Zero(t)
+	}
+	return v.Convert(t)
+}
+
 func AssignerFor(field string, value interface{}) *Assigner {
 	return &Assigner{Field: field, Value: value}
 }
@@ -85,6 +118,12 @@ func ReflectWalkInImpl(curr *reflect.Value, fields []string, f func(*reflect.Val
 				return err
 			}
 		}
+	case reflect.Ptr:
+		if curr.IsNil() {
+			return nil
+		}
+		elem := curr.Elem()
+		return ReflectWalkInImpl(&elem, fields, f)
 	case reflect.Struct:
 		field := curr.FieldByName(fields[0])
 		if !field.IsValid() {
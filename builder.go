@@ -1,6 +1,9 @@
 package querybuilder
 
 import (
+	"encoding/json"
+	"strings"
+
 	"cloud.google.com/go/datastore"
 )
 
@@ -11,12 +14,53 @@ type QueryBuilder struct {
 	Conditions Conditions      `json:"conditions,omitempty"`
 	Filters    []*ValuedFilter `json:"filters,omitempty"`
 	Assigns    Assigners       `json:"assigns,omitempty"`
+	ClientIneq bool            `json:"client_ineq,omitempty"`
+	MaxScan    int             `json:"max_scan,omitempty"`
+	BatchSize  int             `json:"batch_size,omitempty"`
+	Geo        *GeoFilter      `json:"geo,omitempty"`
+	Cursor     string          `json:"cursor,omitempty"`
+	// KeyTieBreaker appends __key__ to the sort orders to make pages stable
+	KeyTieBreaker bool `json:"key_tie_breaker,omitempty"`
+
+	interceptors []Interceptor
+	signedPaging bool // paging is given by PageTokenCodec
+}
+
+// UnmarshalJSON rejects invalid cursors, which Build can't apply.
+func (qb *QueryBuilder) UnmarshalJSON(b []byte) error {
+	type plain QueryBuilder
+	if err := json.Unmarshal(b, (*plain)(qb)); err != nil {
+		return err
+	}
+	return validateCursor(qb.Cursor)
 }
 
 func New(fields ...string) *QueryBuilder {
 	return &QueryBuilder{Fields: fields}
 }
 
+func (qb *QueryBuilder) Clone() *QueryBuilder {
+	r := *qb
+	r.Fields = append(Strings{}, qb.Fields...)
+	r.Ignored = append(Strings{}, qb.Ignored...)
+	r.SortFields = append(Strings{}, qb.SortFields...)
+	r.Conditions = append(Conditions{}, qb.Conditions...)
+	r.Filters = append([]*ValuedFilter{}, qb.Filters...)
+	r.Assigns = append(Assigners{}, qb.Assigns...)
+	r.interceptors = append([]Interceptor{}, qb.interceptors...)
+	if qb.Geo != nil {
+		geo := *qb.Geo
+		r.Geo = &geo
+	}
+	return &r
+}
+
+func (qb *QueryBuilder) WithoutFilters() *QueryBuilder {
+	r := qb.Clone()
+	r.Filters = nil
+	return r
+}
+
 func (qb *QueryBuilder) AddCondition(field string, ope Ope, value interface{}) *QueryBuilder {
 	qb.Conditions = append(qb.Conditions, &Condition{Field: field, Ope: ope, Value: value})
 	return qb
@@ -28,9 +72,15 @@ func (qb *QueryBuilder) AddIntFilter(name string, value int) *QueryBuilder {
 }
 
 func (qb *QueryBuilder) Eq(field string, value interface{}) *QueryBuilder {
-	qb.AddCondition(field, EQ, value)
-	qb.Assigns = append(qb.Assigns, AssignerFor(field, value))
-	qb.Ignored = append(qb.Ignored, field)
+	return qb.EqWithPath(field, field, value)
+}
+
+// EqWithPath works like Eq for a property whose name is different from
+// the path of the struct field, e.g. renamed by datastore tag.
+func (qb *QueryBuilder) EqWithPath(property, path string, value interface{}) *QueryBuilder {
+	qb.AddCondition(property, EQ, value)
+	qb.Assigns = append(qb.Assigns, AssignerFor(path, value))
+	qb.Ignored = append(qb.Ignored, property)
 	return qb
 }
 
@@ -58,10 +108,26 @@ func (qb *QueryBuilder) Ineq(ope Ope, field string, value interface{}) *QueryBui
 	return qb
 }
 
-const utf8LastChar = "\xef\xbf\xbd"
-
 func (qb *QueryBuilder) Starts(field, value string) *QueryBuilder {
-	return qb.Gte(field, value).Lte(field, value+utf8LastChar)
+	qb.Gte(field, value)
+	if upper, ok := PrefixUpperBound(value); ok {
+		qb.Lt(field, upper)
+	}
+	return qb
+}
+
+func (qb *QueryBuilder) StartsBytes(field string, value []byte) *QueryBuilder {
+	qb.Gte(field, value)
+	if upper, ok := BytesPrefixUpperBound(value); ok {
+		qb.Lt(field, upper)
+	}
+	return qb
+}
+
+// StartsFold matches case-insensitively by querying the shadow property
+// named by FoldedField. Its value must be set by AssignFolded on save.
+func (qb *QueryBuilder) StartsFold(field, value string) *QueryBuilder {
+	return qb.Starts(FoldedField(field), Fold(value))
 }
 
 func (qb *QueryBuilder) Asc(field string) *QueryBuilder {
@@ -85,16 +151,98 @@ func (qb *QueryBuilder) Limit(v int) *QueryBuilder {
 	return qb.AddIntFilter("limit", v)
 }
 
+// EvaluateIneqOnClient lets the builder send only one inequality field to
+// Datastore and evaluate the others against the fetched entities.
+// maxScan limits the number of entities fetched for it. 0 means no limit.
+func (qb *QueryBuilder) EvaluateIneqOnClient(maxScan int) *QueryBuilder {
+	qb.ClientIneq = true
+	qb.MaxScan = maxScan
+	return qb
+}
+
+func (qb *QueryBuilder) ServerIneqField() string {
+	fields := qb.Conditions.IneqFields()
+	if len(fields) == 0 {
+		return ""
+	}
+	if len(qb.SortFields) > 0 {
+		first := strings.TrimPrefix(qb.SortFields[0], "-")
+		if fields.Has(first) {
+			return first
+		}
+	}
+	return fields[0]
+}
+
+func (qb *QueryBuilder) ServerConditions() Conditions {
+	if !qb.ClientIneq {
+		return qb.Conditions
+	}
+	field := qb.ServerIneqField()
+	return qb.Conditions.Select(func(c *Condition) bool {
+		return c.Ope == EQ || c.Field == field
+	})
+}
+
+func (qb *QueryBuilder) ClientConditions() Conditions {
+	if !qb.ClientIneq {
+		return Conditions{}
+	}
+	field := qb.ServerIneqField()
+	return qb.Conditions.Select(func(c *Condition) bool {
+		return c.Ope != EQ && c.Field != field
+	})
+}
+
 func (qb *QueryBuilder) ProjectFields() Strings {
-	return qb.Fields.Except(qb.Ignored)
+	r := qb.Fields.Except(qb.Ignored)
+	if len(r) > 0 {
+		for _, f := range qb.ClientConditions().Fields() {
+			if !r.Has(f) {
+				r = append(r, f)
+			}
+		}
+	}
+	return r
+}
+
+func (qb *QueryBuilder) IntFilterValue(name string) (int, bool) {
+	r, ok := 0, false
+	for _, f := range qb.Filters {
+		if f.Name == name {
+			r, ok = f.IntValue, true
+		}
+	}
+	return r, ok
 }
 
+// BuildForCount ignores ClientConditions. Use Executor to count with them.
 func (qb *QueryBuilder) BuildForCount(q *datastore.Query) *datastore.Query {
-	return qb.Conditions.Call(q)
+	q, _ = qb.intercept(CountMode, q, func(_ BuildMode, b *QueryBuilder, q *datastore.Query) (*datastore.Query, Assigners) {
+		return b.ServerConditions().Call(q), b.Assigns
+	})
+	return q
 }
 
 func (qb *QueryBuilder) BuildForList(q *datastore.Query) (*datastore.Query, Assigners) {
-	for _, f := range qb.SortFields {
+	return qb.intercept(ListMode, q, func(_ BuildMode, b *QueryBuilder, q *datastore.Query) (*datastore.Query, Assigners) {
+		q = b.BuildForScan(q)
+		for _, f := range b.Filters {
+			q = f.Call(q)
+		}
+		if b.Cursor != "" {
+			// Cursors are validated when they are set by StartAt, Page,
+			// JSON or proto. Others are rejected by Executor.Prepare.
+			if c, err := datastore.DecodeCursor(b.Cursor); err == nil {
+				q = q.Start(c)
+			}
+		}
+		return q, b.Assigns
+	})
+}
+
+func (qb *QueryBuilder) BuildForScan(q *datastore.Query) *datastore.Query {
+	for _, f := range qb.ListSortFields() {
 		q = q.Order(f)
 	}
 	{
@@ -103,10 +251,7 @@ func (qb *QueryBuilder) BuildForList(q *datastore.Query) (*datastore.Query, Assi
 			q = q.Project(fields...)
 		}
 	}
-	for _, f := range qb.Filters {
-		q = f.Call(q)
-	}
-	return q, qb.Assigns
+	return q
 }
 
 func (qb *QueryBuilder) Build(q *datastore.Query) (*datastore.Query, Assigners) {
@@ -0,0 +1,98 @@
+package querybuilder
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key, kind string, value []byte, ttl time.Duration)
+	InvalidateKind(kind string)
+}
+
+type lruEntry struct {
+	key       string
+	kind      string
+	value     []byte
+	expiresAt time.Time
+}
+
+type LRUCache struct {
+	Capacity int
+	Now      func() time.Time
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		Capacity: capacity,
+		Now:      time.Now,
+		ll:       list.New(),
+		items:    map[string]*list.Element{},
+	}
+}
+
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !c.Now().Before(entry.expiresAt) {
+		c.remove(el)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *LRUCache) Set(key, kind string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.Now().Add(ttl)
+	}
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.kind = kind
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, kind: kind, value: value, expiresAt: expiresAt})
+	for c.Capacity > 0 && c.ll.Len() > c.Capacity {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *LRUCache) InvalidateKind(kind string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.ll.Front(); el != nil; {
+		next := el.Next()
+		if el.Value.(*lruEntry).kind == kind {
+			c.remove(el)
+		}
+		el = next
+	}
+}
+
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *LRUCache) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,75 @@
+package querybuilder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestLRUCache(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)}
+
+	{ // TTL
+		c := NewLRUCache(10)
+		c.Now = clock.Now
+		c.Set("a", Kind4Test, []byte("A"), time.Minute)
+		c.Set("b", Kind4Test, []byte("B"), 0)
+
+		clock.Advance(59 * time.Second)
+		v, ok := c.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, []byte("A"), v)
+
+		clock.Advance(time.Second)
+		_, ok = c.Get("a")
+		assert.False(t, ok)
+
+		clock.Advance(time.Hour)
+		v, ok = c.Get("b")
+		assert.True(t, ok)
+		assert.Equal(t, []byte("B"), v)
+		assert.Equal(t, 1, c.Len())
+	}
+
+	{ // LRU eviction
+		c := NewLRUCache(2)
+		c.Now = clock.Now
+		c.Set("a", Kind4Test, []byte("A"), 0)
+		c.Set("b", Kind4Test, []byte("B"), 0)
+		c.Get("a")
+		c.Set("c", Kind4Test, []byte("C"), 0)
+
+		_, ok := c.Get("b")
+		assert.False(t, ok)
+		_, ok = c.Get("a")
+		assert.True(t, ok)
+		_, ok = c.Get("c")
+		assert.True(t, ok)
+	}
+
+	{ // InvalidateKind
+		c := NewLRUCache(10)
+		c.Now = clock.Now
+		c.Set("a", Kind4Test, []byte("A"), 0)
+		c.Set("b", ComplicatedKind4Test, []byte("B"), 0)
+		c.Set("c", Kind4Test, []byte("C"), 0)
+		c.InvalidateKind(Kind4Test)
+
+		assert.Equal(t, 1, c.Len())
+		_, ok := c.Get("b")
+		assert.True(t, ok)
+	}
+}
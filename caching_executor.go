@@ -0,0 +1,169 @@
+package querybuilder
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+type CachingExecutor struct {
+	*Executor
+	Cache Cache
+	TTL   time.Duration
+}
+
+func NewCachingExecutor(e *Executor, cache Cache, ttl time.Duration) *CachingExecutor {
+	return &CachingExecutor{Executor: e, Cache: cache, TTL: ttl}
+}
+
+// CacheKey returns the key for op of qb. ConditionsFingerprint is a part
+// of it because Fingerprint doesn't distinguish the types of the values.
+func (e *CachingExecutor) CacheKey(op string, qb *QueryBuilder) (string, error) {
+	fp, err := qb.Fingerprint()
+	if err != nil {
+		return "", err
+	}
+	cfp, err := qb.ConditionsFingerprint()
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{"querybuilder", op, e.Namespace, e.Kind, fp, cfp}, "/"), nil
+}
+
+// GetAll caches the results by the fingerprint of qb. Builders with
+// interceptors aren't cached because they can change the query.
+func (e *CachingExecutor) GetAll(ctx context.Context, qb *QueryBuilder, dst interface{}) ([]*datastore.Key, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Unsupported type of destination %T", dst)
+	}
+	qb, err := e.Prepare(qb)
+	if err != nil {
+		return nil, err
+	}
+	if len(qb.interceptors) > 0 {
+		return e.getAll(ctx, qb, dst)
+	}
+	// Results are cached for each type of dst to decode them into
+	key, err := e.CacheKey("list:"+typeKey(v.Type()), qb)
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := e.Cache.Get(key); ok {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+		dec := gob.NewDecoder(bytes.NewReader(b))
+		var keys []*datastore.Key
+		if err := dec.Decode(&keys); err == nil {
+			if err := dec.Decode(dst); err == nil {
+				return keys, nil
+			}
+		}
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+
+	keys, err := e.getAll(ctx, qb, dst)
+	if err != nil {
+		return keys, err // with partial results of ErrMaxScanExceeded
+	}
+	// Results which gob can't encode like unregistered interface values
+	// aren't cached.
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(keys); err != nil {
+		return keys, nil
+	}
+	if err := enc.Encode(v.Elem().Interface()); err != nil {
+		return keys, nil
+	}
+	e.Cache.Set(key, e.Kind, buf.Bytes(), e.TTL)
+	return keys, nil
+}
+
+// typeKey returns the name of t with the package path.
+func typeKey(t reflect.Type) string {
+	prefix := ""
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		if t.Kind() == reflect.Ptr {
+			prefix += "*"
+		} else {
+			prefix += "[]"
+		}
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" {
+		return prefix + t.String()
+	}
+	return prefix + t.PkgPath() + "." + t.Name()
+}
+
+func (e *CachingExecutor) Count(ctx context.Context, qb *QueryBuilder) (int, error) {
+	qb, err := e.Prepare(qb)
+	if err != nil {
+		return 0, err
+	}
+	if len(qb.interceptors) > 0 {
+		return e.count(ctx, qb)
+	}
+	key, err := e.CacheKey("count", qb)
+	if err != nil {
+		return 0, err
+	}
+	if b, ok := e.Cache.Get(key); ok {
+		if c, err := strconv.Atoi(string(b)); err == nil {
+			return c, nil
+		}
+	}
+	c, err := e.count(ctx, qb)
+	if err != nil {
+		return 0, err
+	}
+	e.Cache.Set(key, e.Kind, []byte(strconv.Itoa(c)), e.TTL)
+	return c, nil
+}
+
+// CountUpTo caches the result of Executor.CountUpTo by n and the set of
+// the conditions, so that builders which differ only in the order of the
+// conditions, the sort or paging share the count. Builders with
+// interceptors aren't cached because they can change the conditions.
+func (e *CachingExecutor) CountUpTo(ctx context.Context, qb *QueryBuilder, n int) (*CountResult, error) {
+	qb, err := e.Prepare(qb)
+	if err != nil {
+		return nil, err
+	}
+	if len(qb.interceptors) > 0 {
+		return e.countUpTo(ctx, qb, n)
+	}
+	fp, err := qb.ConditionsFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	key := strings.Join([]string{"querybuilder", "countupto", strconv.Itoa(n), e.Namespace, e.Kind, fp}, "/")
+	if b, ok := e.Cache.Get(key); ok {
+		r := &CountResult{}
+		if err := json.Unmarshal(b, r); err == nil {
+			return r, nil
+		}
+	}
+	r, err := e.countUpTo(ctx, qb, n)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	e.Cache.Set(key, e.Kind, b, e.TTL)
+	return r, nil
+}
+
+func (e *CachingExecutor) Invalidate() {
+	e.Cache.InvalidateKind(e.Kind)
+}
@@ -0,0 +1,159 @@
+package querybuilder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachingExecutor(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)}
+	cache := NewLRUCache(100)
+	cache.Now = clock.Now
+
+	cli := &fakeClient{entities: Entities[:2]}
+	e := NewCachingExecutor(NewExecutor(cli, Kind4Test), cache, time.Minute)
+
+	b := New("Int2", "Str1", "Str2").Eq("Int2", 7)
+
+	getAll := func() ([]*Entity4Test, []*datastore.Key) {
+		var entities []*Entity4Test
+		keys, err := e.GetAll(ctx, b, &entities)
+		assert.NoError(t, err)
+		return entities, keys
+	}
+
+	{
+		entities, keys := getAll()
+		assert.Equal(t, 1, cli.getAllCalls)
+		assert.Equal(t, 2, len(entities))
+		assert.Equal(t, 2, len(keys))
+		assert.Equal(t, 7, entities[0].Int2) // assigned
+	}
+
+	{
+		entities, keys := getAll()
+		assert.Equal(t, 1, cli.getAllCalls) // cached
+		if assert.Equal(t, 2, len(entities)) {
+			assert.Equal(t, "a", entities[0].Str1)
+			assert.Equal(t, "b", entities[1].Str1)
+			assert.Equal(t, 7, entities[1].Int2)
+		}
+		if assert.Equal(t, 2, len(keys)) {
+			assert.Equal(t, int64(2), keys[1].ID)
+		}
+	}
+
+	{ // Different builder is cached separately
+		var entities []*Entity4Test
+		_, err := e.GetAll(ctx, New("Int2", "Str1", "Str2").Eq("Int2", 8), &entities)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, cli.getAllCalls)
+	}
+
+	{ // Types of the values are a part of the key
+		var entities []*Entity4Test
+		_, err := e.GetAll(ctx, New("Int2", "Str1", "Str2").Eq("Int2", 8.0), &entities)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, cli.getAllCalls)
+	}
+
+	{ // Namespace is a part of the key
+		ns := NewCachingExecutor(&Executor{Client: cli, Kind: Kind4Test, Namespace: "other"}, cache, time.Minute)
+		var entities []*Entity4Test
+		_, err := ns.GetAll(ctx, b, &entities)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, cli.getAllCalls)
+	}
+
+	{ // Expired
+		clock.Advance(time.Minute)
+		getAll()
+		assert.Equal(t, 5, cli.getAllCalls)
+		getAll()
+		assert.Equal(t, 5, cli.getAllCalls)
+	}
+
+	{ // Count
+		for i := 0; i < 2; i++ {
+			c, err := e.Count(ctx, b)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, c)
+		}
+		assert.Equal(t, 1, cli.countCalls)
+	}
+
+	{ // Invalidated
+		e.Invalidate()
+		getAll()
+		assert.Equal(t, 6, cli.getAllCalls)
+		_, err := e.Count(ctx, b)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, cli.countCalls)
+	}
+
+	{ // Not cached with interceptors, whose assigns are applied
+		assign := func(next BuildFunc) BuildFunc {
+			return func(mode BuildMode, qb *QueryBuilder, q *datastore.Query) (*datastore.Query, Assigners) {
+				q, assigns := next(mode, qb, q)
+				return q, append(assigns, AssignerFor("Str2", "x"))
+			}
+		}
+		intercepted := NewExecutor(cli, Kind4Test)
+		intercepted.Interceptors = []Interceptor{assign}
+		ie := NewCachingExecutor(intercepted, cache, time.Minute)
+		for i := 0; i < 2; i++ {
+			var entities []*Entity4Test
+			_, err := ie.GetAll(ctx, b, &entities)
+			assert.NoError(t, err)
+			if assert.Equal(t, 2, len(entities)) {
+				assert.Equal(t, "x", entities[0].Str2)
+				assert.Equal(t, 7, entities[0].Int2)
+			}
+			_, err = ie.Count(ctx, b)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 8, cli.getAllCalls)
+		assert.Equal(t, 4, cli.countCalls)
+	}
+
+	{ // Cached for each type of the destination
+		type other struct {
+			Int2 int
+			Str1 string
+		}
+		ocli := &fakeClient{entities: []*other{{Int2: 1, Str1: "x"}}}
+		oe := NewCachingExecutor(NewExecutor(ocli, Kind4Test), cache, time.Minute)
+		for i := 0; i < 2; i++ {
+			var entities []*other
+			_, err := oe.GetAll(ctx, b, &entities)
+			assert.NoError(t, err)
+			if assert.Equal(t, 1, len(entities)) {
+				assert.Equal(t, "x", entities[0].Str1)
+			}
+		}
+		assert.Equal(t, 1, ocli.getAllCalls)
+	}
+
+	{ // Results which can't be encoded aren't cached
+		type unregistered struct{ V int }
+		type withInterface struct {
+			Value interface{}
+		}
+		icli := &fakeClient{entities: []*withInterface{{Value: unregistered{V: 1}}}}
+		ie := NewCachingExecutor(NewExecutor(icli, Kind4Test), cache, time.Minute)
+		for i := 0; i < 2; i++ {
+			var entities []*withInterface
+			keys, err := ie.GetAll(ctx, New(), &entities)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, len(keys))
+			assert.Equal(t, unregistered{V: 1}, entities[0].Value)
+		}
+		assert.Equal(t, 2, icli.getAllCalls)
+	}
+}
@@ -0,0 +1,76 @@
+package querybuilder
+
+// Clause modifies a QueryBuilder. Field and StringField make clauses and
+// querybuilder-gen generates them for the fields of entities.
+type Clause func(*QueryBuilder) *QueryBuilder
+
+func (qb *QueryBuilder) Apply(clauses ...Clause) *QueryBuilder {
+	for _, c := range clauses {
+		qb = c(qb)
+	}
+	return qb
+}
+
+// Field is a property whose values are V. Name is the name of the property
+// and Path is the path of the struct field, which differ when datastore
+// tags rename them.
+type Field[V any] struct {
+	Name string
+	Path string
+}
+
+func (f Field[V]) Eq(v V) Clause {
+	return func(qb *QueryBuilder) *QueryBuilder {
+		return qb.EqWithPath(f.Name, f.Path, v)
+	}
+}
+
+func (f Field[V]) Lt(v V) Clause {
+	return f.Ineq(LT, v)
+}
+
+func (f Field[V]) Lte(v V) Clause {
+	return f.Ineq(LTE, v)
+}
+
+func (f Field[V]) Gt(v V) Clause {
+	return f.Ineq(GT, v)
+}
+
+func (f Field[V]) Gte(v V) Clause {
+	return f.Ineq(GTE, v)
+}
+
+func (f Field[V]) Between(from, to V) Clause {
+	return func(qb *QueryBuilder) *QueryBuilder {
+		return qb.Between(f.Name, from, to)
+	}
+}
+
+func (f Field[V]) Ineq(ope Ope, v V) Clause {
+	return func(qb *QueryBuilder) *QueryBuilder {
+		return qb.Ineq(ope, f.Name, v)
+	}
+}
+
+func (f Field[V]) Asc() Clause {
+	return func(qb *QueryBuilder) *QueryBuilder {
+		return qb.Asc(f.Name)
+	}
+}
+
+func (f Field[V]) Desc() Clause {
+	return func(qb *QueryBuilder) *QueryBuilder {
+		return qb.Desc(f.Name)
+	}
+}
+
+type StringField[V ~string] struct {
+	Field[V]
+}
+
+func (f StringField[V]) Starts(prefix V) Clause {
+	return func(qb *QueryBuilder) *QueryBuilder {
+		return qb.Starts(f.Name, string(prefix))
+	}
+}
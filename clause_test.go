@@ -0,0 +1,31 @@
+package querybuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClauses(t *testing.T) {
+	int1 := Field[int]{Name: "Int1", Path: "Int1"}
+	enumA := Field[EnumA]{Name: "EnumA", Path: "EnumA"}
+	str2 := StringField[string]{Field[string]{Name: "Str2", Path: "Str2"}}
+	code := StringField[string]{Field[string]{Name: "sub.code", Path: "Sub.Code"}}
+
+	b := New().Apply(
+		enumA.Eq(EnumA2),
+		int1.Gte(2),
+		str2.Starts("ba"),
+		code.Eq("X"),
+		int1.Desc(),
+	)
+	assert.Equal(t, Conditions{
+		{"EnumA", EQ, EnumA2},
+		{"Int1", GTE, 2},
+		{"Str2", GTE, "ba"},
+		{"Str2", LT, "bb"},
+		{"sub.code", EQ, "X"},
+	}, b.Conditions)
+	assert.Equal(t, Strings{"Str2", "Int1", "-Int1"}, b.SortFields)
+	assert.Equal(t, Assigners{AssignerFor("EnumA", EnumA2), AssignerFor("Sub.Code", "X")}, b.Assigns)
+}
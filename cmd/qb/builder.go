@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/akm/querybuilder"
+)
+
+// load validates data against the JSON Schema and decodes it.
+// Integral numbers are taken as integers because JSON doesn't tell them
+// from floats.
+func load(data []byte) (*querybuilder.QueryBuilder, error) {
+	if err := querybuilder.ValidateJSON(data); err != nil {
+		return nil, fmt.Errorf("Invalid builder JSON:\n%v", err)
+	}
+	qb := &querybuilder.QueryBuilder{}
+	if err := json.Unmarshal(data, qb); err != nil {
+		return nil, fmt.Errorf("Invalid builder JSON: %v", err)
+	}
+	for _, c := range qb.Conditions {
+		c.Value = integral(c.Value)
+	}
+	for _, a := range qb.Assigns {
+		a.Value = integral(a.Value)
+	}
+	if params := qb.Params(); len(params) > 0 {
+		return nil, fmt.Errorf("Unbound params: %v", params)
+	}
+	return qb, nil
+}
+
+func integral(v interface{}) interface{} {
+	switch x := v.(type) {
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<53 {
+			return int64(x)
+		}
+	case []interface{}:
+		for i := range x {
+			x[i] = integral(x[i])
+		}
+	}
+	return v
+}
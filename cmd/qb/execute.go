@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/akm/querybuilder"
+)
+
+const keyColumn = "__key__"
+
+func execute(ctx context.Context, opts *options, qb *querybuilder.QueryBuilder, w io.Writer) error {
+	if opts.project == "" {
+		opts.project = datastore.DetectProjectID
+	}
+	cli, err := datastore.NewClient(ctx, opts.project)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	return runQuery(ctx, cli, opts, qb, w)
+}
+
+func runQuery(ctx context.Context, cli querybuilder.Client, opts *options, qb *querybuilder.QueryBuilder, w io.Writer) error {
+	e := querybuilder.NewExecutor(cli, opts.kind)
+	e.Namespace = opts.namespace
+
+	if opts.count {
+		c, err := e.Count(ctx, qb)
+		if err != nil {
+			return err
+		}
+		if opts.format == "json" {
+			return json.NewEncoder(w).Encode(map[string]int{"count": c})
+		}
+		_, err = fmt.Fprintln(w, c)
+		return err
+	}
+
+	if len(qb.ClientConditions()) > 0 || qb.Geo != nil {
+		return fmt.Errorf("qb doesn't support conditions evaluated on client nor geo filter")
+	}
+	if opts.keysOnly {
+		// Datastore can't run a projection query as keys only
+		b := qb.Clone()
+		b.Fields = nil
+		q, _ := b.Build(e.NewQuery())
+		keys, err := cli.GetAll(ctx, q.KeysOnly(), nil)
+		if err != nil {
+			return err
+		}
+		return writeKeys(w, opts.format, keys)
+	}
+
+	q, assigns := qb.Build(e.NewQuery())
+	var rows []datastore.PropertyList
+	keys, err := cli.GetAll(ctx, q, &rows)
+	if err != nil {
+		return err
+	}
+	if len(qb.ProjectFields()) > 0 {
+		if keys, err = querybuilder.MergeRowsByKey(keys, &rows); err != nil {
+			return err
+		}
+	}
+	for i := range rows {
+		assign(&rows[i], assigns)
+	}
+	return writeRows(w, opts.format, keys, rows)
+}
+
+// assign adds the values of assigns which the rows don't have because of
+// projection.
+func assign(row *datastore.PropertyList, assigns querybuilder.Assigners) {
+	for _, a := range assigns {
+		found := false
+		for _, p := range *row {
+			if p.Name == a.Field {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*row = append(*row, datastore.Property{Name: a.Field, Value: a.Value})
+		}
+	}
+}
+
+func writeKeys(w io.Writer, format string, keys []*datastore.Key) error {
+	if format == "json" {
+		strs := make([]string, len(keys))
+		for i, key := range keys {
+			strs[i] = key.String()
+		}
+		return json.NewEncoder(w).Encode(strs)
+	}
+	for _, key := range keys {
+		if _, err := fmt.Fprintln(w, key.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeRows(w io.Writer, format string, keys []*datastore.Key, rows []datastore.PropertyList) error {
+	columns := []string{}
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for _, p := range row {
+			if !seen[p.Name] {
+				seen[p.Name] = true
+				columns = append(columns, p.Name)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	objects := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		obj := map[string]interface{}{keyColumn: keys[i].String()}
+		for _, p := range row {
+			v := jsonValue(p.Value)
+			if prev, ok := obj[p.Name]; ok {
+				if vs, ok := prev.([]interface{}); ok {
+					v = append(vs, v)
+				} else {
+					v = []interface{}{prev, v}
+				}
+			}
+			obj[p.Name] = v
+		}
+		objects[i] = obj
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(objects)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, keyColumn+"\t"+strings.Join(columns, "\t"))
+	for _, obj := range objects {
+		cells := []string{obj[keyColumn].(string)}
+		for _, c := range columns {
+			cells = append(cells, cell(obj[c]))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func jsonValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case *datastore.Key:
+		return x.String()
+	case []interface{}:
+		r := make([]interface{}, len(x))
+		for i, e := range x {
+			r[i] = jsonValue(e)
+		}
+		return r
+	case *datastore.Entity:
+		m := map[string]interface{}{}
+		for _, p := range x.Properties {
+			m[p.Name] = jsonValue(p.Value)
+		}
+		return m
+	default:
+		return v
+	}
+}
+
+func cell(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case []interface{}, map[string]interface{}:
+		b, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Sprintf("%v", x)
+		}
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", x)
+	}
+}
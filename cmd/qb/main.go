@@ -0,0 +1,108 @@
+// qb runs a QueryBuilder serialized in JSON against Datastore.
+//
+//	qb -project my-project -kind Entity [options] [file]
+//
+// The builder is read from stdin if file is omitted or "-". The local
+// emulator is used when DATASTORE_EMULATOR_HOST is set.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+type options struct {
+	project   string
+	namespace string
+	kind      string
+	format    string
+	count     bool
+	keysOnly  bool
+	explain   bool
+	validate  bool
+	path      string
+}
+
+func main() {
+	opts, err := parseFlags(os.Args[1:], os.Stderr)
+	if err != nil {
+		os.Exit(2)
+	}
+	if err := run(context.Background(), opts, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "qb: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func parseFlags(args []string, stderr io.Writer) (*options, error) {
+	opts := &options{}
+	fs := flag.NewFlagSet("qb", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.project, "project", os.Getenv("DATASTORE_PROJECT_ID"), "Google Cloud project ID")
+	fs.StringVar(&opts.namespace, "namespace", "", "namespace of the entities")
+	fs.StringVar(&opts.kind, "kind", "", "kind of the entities")
+	fs.StringVar(&opts.format, "format", "table", "output format: table or json")
+	fs.BoolVar(&opts.count, "count", false, "print the number of the entities")
+	fs.BoolVar(&opts.keysOnly, "keys-only", false, "print the keys of the entities")
+	fs.BoolVar(&opts.explain, "explain", false, "print the GQL instead of running it")
+	fs.BoolVar(&opts.validate, "validate", false, "validate the builder without running it")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: qb [options] [file]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	switch fs.NArg() {
+	case 0:
+		opts.path = "-"
+	case 1:
+		opts.path = fs.Arg(0)
+	default:
+		fs.Usage()
+		return nil, fmt.Errorf("Too many arguments: %v", fs.Args())
+	}
+	return opts, nil
+}
+
+func run(ctx context.Context, opts *options, stdin io.Reader, stdout io.Writer) error {
+	var data []byte
+	var err error
+	if opts.path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(opts.path)
+	}
+	if err != nil {
+		return err
+	}
+	qb, err := load(data)
+	if err != nil {
+		return err
+	}
+
+	if opts.validate {
+		fmt.Fprintln(stdout, "OK")
+		return nil
+	}
+	if opts.kind == "" {
+		return fmt.Errorf("-kind is required")
+	}
+	if opts.explain {
+		if opts.count {
+			fmt.Fprintln(stdout, qb.CountGQL(opts.kind))
+		} else {
+			fmt.Fprintln(stdout, qb.GQL(opts.kind))
+		}
+		return nil
+	}
+	switch opts.format {
+	case "table", "json":
+	default:
+		return fmt.Errorf("Unknown format: %s", opts.format)
+	}
+	return execute(ctx, opts, qb, stdout)
+}
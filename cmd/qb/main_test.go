@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClient struct {
+	rows []datastore.PropertyList
+}
+
+func (c *fakeClient) GetAll(ctx context.Context, q *datastore.Query, dst interface{}) ([]*datastore.Key, error) {
+	// Datastore rejects keys only queries with projection
+	if dst == nil && reflect.ValueOf(q).Elem().FieldByName("projection").Len() > 0 {
+		return nil, errors.New("keys only query with projection")
+	}
+	keys := []*datastore.Key{}
+	for i, row := range c.rows {
+		key := datastore.IDKey("entity4test", int64(i/2+1), nil)
+		// Keys only queries return a key for each entity
+		if dst == nil && len(keys) > 0 && keys[len(keys)-1].Equal(key) {
+			continue
+		}
+		keys = append(keys, key)
+		if dst != nil {
+			d := dst.(*[]datastore.PropertyList)
+			*d = append(*d, row)
+		}
+	}
+	return keys, nil
+}
+
+func (c *fakeClient) Count(ctx context.Context, q *datastore.Query) (int, error) {
+	return len(c.rows), nil
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	simpleEq := filepath.Join("..", "..", "builder_test", "simple_eq.json")
+
+	{ // Validate
+		var out bytes.Buffer
+		assert.NoError(t, run(ctx, &options{validate: true, path: simpleEq}, nil, &out))
+		assert.Equal(t, "OK\n", out.String())
+	}
+
+	{ // Explain
+		var out bytes.Buffer
+		assert.NoError(t, run(ctx, &options{kind: "entity4test", explain: true, path: simpleEq}, nil, &out))
+		assert.Equal(t, "SELECT Str1, Str2 FROM entity4test WHERE Int2 = 1\n", out.String())
+	}
+
+	{ // Explain count from stdin
+		var out bytes.Buffer
+		in := strings.NewReader(`{"conditions":[{"field":"Int1","ope":">=","value":2}]}`)
+		assert.NoError(t, run(ctx, &options{kind: "entity4test", explain: true, count: true, path: "-"}, in, &out))
+		assert.Equal(t, "AGGREGATE COUNT(*) OVER (SELECT * FROM entity4test WHERE Int1 >= 2)\n", out.String())
+	}
+
+	{ // Invalid builders
+		for _, src := range []string{
+			`{"unknown":1}`,
+			`{"conditions":[{"field":"Int1","ope":"~","value":2}]}`,
+			`{"filters":[{"name":"skip","value":2}]}`,
+			`{"conditions":[{"field":"Int1","ope":"=","value":{"param":"p"}}]}`,
+		} {
+			err := run(ctx, &options{validate: true, path: "-"}, strings.NewReader(src), &bytes.Buffer{})
+			assert.Error(t, err, src)
+		}
+	}
+
+	{ // Kind is required
+		err := run(ctx, &options{explain: true, path: simpleEq}, nil, &bytes.Buffer{})
+		assert.Error(t, err)
+	}
+}
+
+func TestRunQuery(t *testing.T) {
+	ctx := context.Background()
+	src, err := os.ReadFile(filepath.Join("..", "..", "builder_test", "simple_eq.json"))
+	assert.NoError(t, err)
+	qb, err := load(src)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), qb.Conditions[0].Value)
+
+	cli := &fakeClient{rows: []datastore.PropertyList{
+		{{Name: "Str1", Value: "a"}, {Name: "Str2", Value: "foo"}},
+		{{Name: "Str1", Value: "a"}, {Name: "Str2", Value: "bar"}},
+		{{Name: "Str1", Value: "b"}, {Name: "Str2", Value: "baz"}},
+	}}
+
+	{
+		var out bytes.Buffer
+		assert.NoError(t, runQuery(ctx, cli, &options{kind: "entity4test", format: "table"}, qb, &out))
+		assert.Equal(t, strings.Join([]string{
+			"__key__         Int2  Str1  Str2",
+			"/entity4test,1  1     a     [\"foo\",\"bar\"]",
+			"/entity4test,2  1     b     baz",
+			"",
+		}, "\n"), out.String())
+	}
+
+	{
+		var out bytes.Buffer
+		assert.NoError(t, runQuery(ctx, cli, &options{kind: "entity4test", format: "json"}, qb, &out))
+		assert.JSONEq(t, `[
+			{"__key__": "/entity4test,1", "Int2": 1, "Str1": "a", "Str2": ["foo", "bar"]},
+			{"__key__": "/entity4test,2", "Int2": 1, "Str1": "b", "Str2": "baz"}
+		]`, out.String())
+	}
+
+	{ // Values which can't be compared by ==
+		cli := &fakeClient{rows: []datastore.PropertyList{
+			{{Name: "Str1", Value: "a"}, {Name: "Ref", Value: datastore.NameKey("Ref", "x", nil)}, {Name: "Data", Value: []byte("d")}, {Name: "Tags", Value: []interface{}{"t"}}},
+			{{Name: "Str1", Value: "a"}, {Name: "Ref", Value: datastore.NameKey("Ref", "x", nil)}, {Name: "Data", Value: []byte("d")}, {Name: "Tags", Value: []interface{}{"t"}}},
+		}}
+		var out bytes.Buffer
+		assert.NoError(t, runQuery(ctx, cli, &options{kind: "entity4test", format: "json"}, qb, &out))
+		assert.JSONEq(t, `[
+			{"__key__": "/entity4test,1", "Int2": 1, "Str1": "a", "Ref": "/Ref,x", "Data": "ZA==", "Tags": ["t"]}
+		]`, out.String())
+	}
+
+	{
+		var out bytes.Buffer
+		assert.NoError(t, runQuery(ctx, cli, &options{kind: "entity4test", format: "table", keysOnly: true}, qb, &out))
+		assert.Equal(t, "/entity4test,1\n/entity4test,2\n", out.String())
+	}
+
+	{
+		var out bytes.Buffer
+		assert.NoError(t, runQuery(ctx, cli, &options{kind: "entity4test", format: "json", count: true}, qb, &out))
+		assert.Equal(t, "{\"count\":3}\n", out.String())
+	}
+}
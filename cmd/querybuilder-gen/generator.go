@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	generatedMark   = "Code generated by querybuilder-gen. DO NOT EDIT."
+	querybuilderPkg = "github.com/akm/querybuilder"
+)
+
+var basicTypes = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true,
+}
+
+type node struct {
+	GoName   string
+	Name     string // property name
+	Path     string // struct field path
+	TypeExpr string
+	String   bool
+	TypeName string // name of the generated struct type for nested fields
+	Children []*node
+}
+
+func (n *node) Nested() bool {
+	return n.TypeName != ""
+}
+
+func (n *node) fieldType(qb string) string {
+	switch {
+	case n.Nested():
+		return n.TypeName
+	case n.String:
+		return fmt.Sprintf("%sStringField[%s]", qb, n.TypeExpr)
+	default:
+		return fmt.Sprintf("%sField[%s]", qb, n.TypeExpr)
+	}
+}
+
+func (n *node) writeLiteral(buf *bytes.Buffer, qb string) {
+	fmt.Fprintf(buf, "%s{\n", n.TypeName)
+	for _, c := range n.Children {
+		fmt.Fprintf(buf, "%s: ", c.GoName)
+		switch {
+		case c.Nested():
+			c.writeLiteral(buf, qb)
+		case c.String:
+			fmt.Fprintf(buf, "%s{Field: %sField[%s]{Name: %q, Path: %q}}", c.fieldType(qb), qb, c.TypeExpr, c.Name, c.Path)
+		default:
+			fmt.Fprintf(buf, "%s{Name: %q, Path: %q}", c.fieldType(qb), c.Name, c.Path)
+		}
+		buf.WriteString(",\n")
+	}
+	buf.WriteString("}")
+}
+
+type generator struct {
+	pkgName   string
+	typeSpecs map[string]*ast.TypeSpec
+	imports   map[string]string // name to path
+	used      map[string]bool   // paths
+	types     []*node           // nested types to be declared
+}
+
+func Generate(dir string, typeNames []string) ([]byte, error) {
+	g := &generator{
+		typeSpecs: map[string]*ast.TypeSpec{},
+		imports:   map[string]string{},
+		used:      map[string]bool{},
+	}
+	if err := g.parseDir(dir); err != nil {
+		return nil, err
+	}
+
+	roots := []*node{}
+	for _, name := range typeNames {
+		name = strings.TrimSpace(name)
+		spec, ok := g.typeSpecs[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in %s", name, dir)
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return nil, fmt.Errorf("type %s is not a struct", name)
+		}
+		root := &node{GoName: name + "Q", TypeName: unexport(name) + "Q"}
+		g.types = append(g.types, root)
+		root.Children = g.fields(st, "", "", root.TypeName, map[string]bool{name: true})
+		roots = append(roots, root)
+	}
+	return g.render(roots)
+}
+
+func (g *generator) parseDir(dir string) error {
+	fset := token.NewFileSet()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(src, []byte(generatedMark)) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		if g.pkgName == "" {
+			g.pkgName = f.Name.Name
+		}
+		for _, imp := range f.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			name := p[strings.LastIndex(p, "/")+1:]
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			g.imports[name] = p
+		}
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				g.typeSpecs[ts.Name.Name] = ts
+			}
+		}
+	}
+	if g.pkgName == "" {
+		return fmt.Errorf("no Go files in %s", dir)
+	}
+	return nil
+}
+
+func (g *generator) fields(st *ast.StructType, namePrefix, pathPrefix, typeName string, visiting map[string]bool) []*node {
+	r := []*node{}
+	for _, f := range st.Fields.List {
+		name, options := "", []string{}
+		if f.Tag != nil {
+			tag, _ := strconv.Unquote(f.Tag.Value)
+			if v, ok := reflect.StructTag(tag).Lookup("datastore"); ok {
+				parts := strings.Split(v, ",")
+				name, options = parts[0], parts[1:]
+			}
+		}
+		if name == "-" || contains(options, "noindex") {
+			continue
+		}
+
+		if len(f.Names) == 0 { // embedded
+			if name == "" {
+				if st, id, ok := g.structOf(f.Type); ok && !visiting[id] {
+					visiting[id] = true
+					r = append(r, g.fields(st, namePrefix, pathPrefix, typeName, visiting)...)
+					delete(visiting, id)
+				}
+				continue
+			}
+			f.Names = []*ast.Ident{ast.NewIdent(embeddedName(f.Type))}
+		}
+
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			prop := name
+			if prop == "" {
+				prop = ident.Name
+			}
+			n := &node{
+				GoName: ident.Name,
+				Name:   namePrefix + prop,
+				Path:   pathPrefix + ident.Name,
+			}
+			if st, id, ok := g.structOf(f.Type); ok {
+				if visiting[id] {
+					continue
+				}
+				visiting[id] = true
+				n.TypeName = typeName + ident.Name
+				g.types = append(g.types, n)
+				n.Children = g.fields(st, n.Name+".", n.Path+".", n.TypeName, visiting)
+				delete(visiting, id)
+			} else {
+				expr, str, ok := g.valueType(f.Type)
+				if !ok {
+					continue
+				}
+				n.TypeExpr, n.String = expr, str
+			}
+			r = append(r, n)
+		}
+	}
+	return r
+}
+
+// structOf returns the struct type of the nested entity in expr.
+func (g *generator) structOf(expr ast.Expr) (*ast.StructType, string, bool) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return g.structOf(t.X)
+	case *ast.ArrayType:
+		return g.structOf(t.Elt)
+	case *ast.StructType:
+		return t, fmt.Sprintf("%p", t), true
+	case *ast.Ident:
+		if spec, ok := g.typeSpecs[t.Name]; ok {
+			if st, ok := spec.Type.(*ast.StructType); ok {
+				return st, t.Name, true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// valueType returns the Go expression of the type of the values and
+// whether its underlying type is string.
+func (g *generator) valueType(expr ast.Expr) (string, bool, bool) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		if sel, ok := t.X.(*ast.SelectorExpr); ok && g.selector(sel) == "datastore.Key" {
+			return "*" + g.selector(sel), false, true
+		}
+		return g.valueType(t.X)
+	case *ast.ArrayType:
+		if id, ok := t.Elt.(*ast.Ident); ok && (id.Name == "byte" || id.Name == "uint8") {
+			return "[]byte", false, true
+		}
+		return g.valueType(t.Elt)
+	case *ast.Ident:
+		if basicTypes[t.Name] {
+			return t.Name, t.Name == "string", true
+		}
+		spec, ok := g.typeSpecs[t.Name]
+		if !ok {
+			return "", false, false
+		}
+		switch u := spec.Type.(type) {
+		case *ast.Ident, *ast.SelectorExpr:
+			_, str, ok := g.valueType(u)
+			return t.Name, str, ok
+		}
+		return "", false, false
+	case *ast.SelectorExpr:
+		return g.selector(t), false, true
+	default:
+		return "", false, false
+	}
+}
+
+func (g *generator) selector(sel *ast.SelectorExpr) string {
+	pkg := sel.X.(*ast.Ident).Name
+	if path, ok := g.imports[pkg]; ok {
+		g.used[path] = true
+	}
+	return pkg + "." + sel.Sel.Name
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+func unexport(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+func contains(s []string, v string) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) render(roots []*node) ([]byte, error) {
+	qb := "querybuilder."
+	if g.pkgName == "querybuilder" {
+		qb = ""
+	} else {
+		g.used[querybuilderPkg] = true
+	}
+	std, others := []string{}, []string{}
+	for path := range g.used {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			others = append(others, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// %s\n\npackage %s\n\n", generatedMark, g.pkgName)
+	if len(std)+len(others) > 0 {
+		buf.WriteString("import (\n")
+		for _, path := range std {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+		if len(std) > 0 && len(others) > 0 {
+			buf.WriteString("\n")
+		}
+		for _, path := range others {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+		buf.WriteString(")\n\n")
+	}
+	for _, t := range g.types {
+		fmt.Fprintf(&buf, "type %s struct {\n", t.TypeName)
+		for _, c := range t.Children {
+			fmt.Fprintf(&buf, "\t%s %s\n", c.GoName, c.fieldType(qb))
+		}
+		buf.WriteString("}\n\n")
+	}
+	for _, root := range roots {
+		fmt.Fprintf(&buf, "var %s = ", root.GoName)
+		root.writeLiteral(&buf, qb)
+		buf.WriteString("\n\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, buf.String())
+	}
+	return src, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("testdata", "models")
+	golden := filepath.Join("testdata", "models.golden")
+
+	src, err := Generate(dir, []string{"Entity4Test", "ComplicatedEntity4Test"})
+	assert.NoError(t, err)
+	if *update {
+		assert.NoError(t, os.WriteFile(golden, src, 0644))
+	}
+	expected, err := os.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(src))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir := filepath.Join("testdata", "models")
+	{
+		_, err := Generate(dir, []string{"Unknown"})
+		assert.Error(t, err)
+	}
+	{
+		_, err := Generate(dir, []string{"EnumA"})
+		assert.Error(t, err)
+	}
+}
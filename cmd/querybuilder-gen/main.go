@@ -0,0 +1,42 @@
+// querybuilder-gen generates accessors of the fields of entities which make
+// querybuilder.Clause values. Use it with go generate like this:
+//
+//	//go:generate querybuilder-gen -type Entity,Other
+//
+// It writes querybuilder_gen.go by default.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of type names")
+	output := flag.String("output", "querybuilder_gen.go", "output file name")
+	dir := flag.String("dir", ".", "directory of the package")
+	flag.Parse()
+
+	if *typeNames == "" {
+		fmt.Fprintln(os.Stderr, "querybuilder-gen: -type is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	src, err := Generate(*dir, strings.Split(*typeNames, ","))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "querybuilder-gen: %v\n", err)
+		os.Exit(1)
+	}
+	path := *output
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(*dir, path)
+	}
+	if err := os.WriteFile(path, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "querybuilder-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
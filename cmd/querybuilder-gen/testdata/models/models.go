@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+type EnumA string
+
+type Entity4Test struct {
+	Int1  int
+	Int2  int
+	Str1  string
+	Str2  string
+	EnumA EnumA
+}
+
+type SubEntity struct {
+	I1 int
+	S1 string
+}
+
+type Timestamps struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time `datastore:"updated_at"`
+}
+
+type ComplicatedEntity4Test struct {
+	Timestamps
+	ID       int    `datastore:"id"`
+	Name     string `datastore:"name"`
+	Memo     string `datastore:",noindex"`
+	Secret   string `datastore:"-"`
+	Strings  []string
+	Data     []byte
+	Location datastore.GeoPoint
+	Parent   *datastore.Key
+	Sub1     SubEntity
+	Subs     []SubEntity
+	private  int
+}
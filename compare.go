@@ -0,0 +1,181 @@
+package querybuilder
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func CompareValues(a, b interface{}) (int, error) {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		return 0, fmt.Errorf("Can't compare %v with %v", a, b)
+	}
+	if ka, ok := a.(*datastore.Key); ok {
+		if kb, ok := b.(*datastore.Key); ok {
+			return compareKeys(ka, kb), nil
+		}
+	}
+	if va.Type() == timeType && vb.Type() == timeType {
+		ta, tb := a.(time.Time), b.(time.Time)
+		switch {
+		case ta.Before(tb):
+			return -1, nil
+		case ta.After(tb):
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	}
+	switch {
+	case isIntKind(va.Kind()) && isIntKind(vb.Kind()):
+		return compareInts(va, vb), nil
+	case isNumberKind(va.Kind()) && isNumberKind(vb.Kind()):
+		return compareFloat64(toFloat64(va), toFloat64(vb)), nil
+	case va.Kind() == reflect.String && vb.Kind() == reflect.String:
+		return strings.Compare(va.String(), vb.String()), nil
+	case va.Kind() == reflect.Bool && vb.Kind() == reflect.Bool:
+		return compareInt64(boolToInt64(va.Bool()), boolToInt64(vb.Bool())), nil
+	default:
+		return 0, fmt.Errorf("Can't compare %T with %T", a, b)
+	}
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return isIntKind(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// compareInts compares unsigned values as unsigned, which may not fit
+// in int64.
+func compareInts(a, b reflect.Value) int {
+	ua, ub := isUintKind(a.Kind()), isUintKind(b.Kind())
+	switch {
+	case ua && ub:
+		switch {
+		case a.Uint() < b.Uint():
+			return -1
+		case a.Uint() > b.Uint():
+			return 1
+		default:
+			return 0
+		}
+	case ua:
+		return -compareInts(b, a)
+	case ub:
+		if a.Int() < 0 || b.Uint() > math.MaxInt64 {
+			return -1
+		}
+		return compareInt64(a.Int(), int64(b.Uint()))
+	default:
+		return compareInt64(a.Int(), b.Int())
+	}
+}
+
+func toInt64(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	default:
+		return int64(v.Uint())
+	}
+}
+
+func toFloat64(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	default:
+		return float64(v.Int())
+	}
+}
+
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareKeys compares keys in the order of Datastore, which compares the
+// kinds and the IDs or names from the root. IDs are before names.
+func compareKeys(a, b *datastore.Key) int {
+	pa, pb := keyPath(a), keyPath(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		ka, kb := pa[i], pb[i]
+		if c := strings.Compare(ka.Kind, kb.Kind); c != 0 {
+			return c
+		}
+		switch {
+		case ka.Name == "" && kb.Name == "":
+			if c := compareInt64(ka.ID, kb.ID); c != 0 {
+				return c
+			}
+		case ka.Name == "":
+			return -1
+		case kb.Name == "":
+			return 1
+		default:
+			if c := strings.Compare(ka.Name, kb.Name); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt64(int64(len(pa)), int64(len(pb)))
+}
+
+func keyPath(k *datastore.Key) []*datastore.Key {
+	r := []*datastore.Key{}
+	for ; k != nil; k = k.Parent {
+		r = append([]*datastore.Key{k}, r...)
+	}
+	return r
+}
@@ -0,0 +1,32 @@
+package querybuilder
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareValues(t *testing.T) {
+	patterns := []struct {
+		a, b     interface{}
+		expected int
+	}{
+		{1, 2, -1},
+		{int64(-1), uint8(0), -1},
+		{uint64(math.MaxUint64), int64(math.MaxInt64), 1},
+		{int64(math.MaxInt64), uint64(math.MaxInt64 + 1), -1},
+		{uint64(math.MaxUint64), uint64(math.MaxUint64 - 1), 1},
+		{uint64(math.MaxUint64), 1.5, 1},
+		{uint(3), 3, 0},
+		{"a", "b", -1},
+	}
+	for _, ptn := range patterns {
+		r, err := CompareValues(ptn.a, ptn.b)
+		assert.NoError(t, err)
+		assert.Equal(t, ptn.expected, r, "%v <=> %v", ptn.a, ptn.b)
+	}
+
+	_, err := CompareValues(1, "a")
+	assert.Error(t, err)
+}
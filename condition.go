@@ -1,6 +1,7 @@
 package querybuilder
 
 import (
+	"encoding/json"
 	"reflect"
 
 	"cloud.google.com/go/datastore"
@@ -12,6 +13,31 @@ type Condition struct {
 	Value interface{} `json:"value"`
 }
 
+type conditionJSON struct {
+	Field string      `json:"field"`
+	Ope   Ope         `json:"ope"`
+	Value interface{} `json:"value"`
+	Type  string      `json:"type,omitempty"`
+}
+
+func (c *Condition) MarshalJSON() ([]byte, error) {
+	v, typ := TypedValue(c.Value)
+	return marshalJSON(&conditionJSON{Field: c.Field, Ope: c.Ope, Value: v, Type: typ})
+}
+
+func (c *Condition) UnmarshalJSON(b []byte) error {
+	var src conditionJSON
+	if err := json.Unmarshal(b, &src); err != nil {
+		return err
+	}
+	v, err := UntypedValue(src.Value, src.Type)
+	if err != nil {
+		return err
+	}
+	c.Field, c.Ope, c.Value = src.Field, src.Ope, v
+	return nil
+}
+
 func (c *Condition) Call(q *datastore.Query) *datastore.Query {
 	return q.Filter(c.Field+c.Ope.String(), c.OriginalTypeValue())
 }
@@ -35,6 +61,9 @@ var primitiveTypeMap = map[reflect.Kind]reflect.Type{
 
 func (c *Condition) OriginalTypeValue() interface{} {
 	v := reflect.ValueOf(c.Value)
+	if !v.IsValid() { // null
+		return nil
+	}
 	pt, ok := primitiveTypeMap[v.Type().Kind()]
 	if ok && pt != nil {
 		return v.Convert(pt).Interface()
@@ -42,3 +71,41 @@ func (c *Condition) OriginalTypeValue() interface{} {
 		return c.Value
 	}
 }
+
+func (c *Condition) Match(entity interface{}) (bool, error) {
+	e := reflect.ValueOf(entity)
+	if e.Type().Kind() == reflect.Ptr {
+		e = e.Elem()
+	}
+	matched := false
+	match := func(v reflect.Value) error {
+		r, err := CompareValues(v.Interface(), c.Value)
+		if err != nil {
+			return err
+		}
+		if c.Ope.Match(r) {
+			matched = true
+		}
+		return nil
+	}
+	err := ReflectWalkIn(&e, c.Field, ".", func(f *reflect.Value) error {
+		if matched {
+			return nil
+		}
+		// Multi-valued properties match when any of their values matches
+		if f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8 {
+			l := f.Len()
+			for i := 0; i < l && !matched; i++ {
+				if err := match(f.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return match(*f)
+	})
+	if err != nil {
+		return false, err
+	}
+	return matched, nil
+}
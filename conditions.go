@@ -28,3 +28,34 @@ func (s Conditions) IneqFields() Strings {
 func (s Conditions) HasMultipleIneqFields() bool {
 	return len(s.IneqFields()) > 1
 }
+
+func (s Conditions) Select(f ConditionPredict) Conditions {
+	r := Conditions{}
+	for _, i := range s {
+		if f(i) {
+			r = append(r, i)
+		}
+	}
+	return r
+}
+
+func (s Conditions) Fields() Strings {
+	r := Strings{}
+	for _, i := range s {
+		r = append(r, i.Field)
+	}
+	return r.Uniq()
+}
+
+func (s Conditions) Match(entity interface{}) (bool, error) {
+	for _, i := range s {
+		ok, err := i.Match(entity)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
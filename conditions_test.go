@@ -30,3 +30,39 @@ func TestConditions(t *testing.T) {
 	}
 
 }
+
+func TestConditionsMatch(t *testing.T) {
+	e := ComplicatedEntities[4] // Quux
+	patterns := map[*Condition]bool{
+		{"ID", EQ, 5}:         true,
+		{"ID", GT, 4.5}:       true,
+		{"ID", LT, 5}:         false,
+		{"Name", GTE, "Qu"}:   true,
+		{"Name", LT, "Quux"}:  false,
+		{"Strings", EQ, "d"}:  true,
+		{"Subs.I1", GTE, 3}:   true,
+		{"Subs.I1", GT, 3}:    false,
+		{"Subs.S1", LTE, "A"}: true,
+	}
+	for c, expected := range patterns {
+		ok, err := c.Match(e)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ok, "%v", c)
+	}
+
+	{
+		ok, err := Conditions{{"ID", GT, 1}, {"Subs.I1", EQ, 1}}.Match(e)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = Conditions{{"ID", GT, 1}, {"Subs.I1", EQ, 2}}.Match(e)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	}
+
+	{
+		_, err := (&Condition{"Name", EQ, 1}).Match(e)
+		assert.Error(t, err)
+		_, err = (&Condition{"Unknown", EQ, 1}).Match(e)
+		assert.Error(t, err)
+	}
+}
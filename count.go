@@ -0,0 +1,205 @@
+package querybuilder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+// AggregationClient is implemented by clients which run aggregation queries
+// on the server like *datastore.Client.
+type AggregationClient interface {
+	RunAggregationQuery(ctx context.Context, aq *datastore.AggregationQuery) (datastore.AggregationResult, error)
+}
+
+const (
+	StatKind   = "__Stat_Kind__"
+	StatNsKind = "__Stat_Ns_Kind__"
+
+	countAlias = "count"
+)
+
+var ErrNoKindStat = errors.New("No statistics of the kind")
+
+// KindStat is the entity of the built-in statistics of a kind.
+// Statistics are updated by Datastore only once in a while.
+type KindStat struct {
+	Count     int64     `datastore:"count"`
+	Bytes     int64     `datastore:"bytes"`
+	KindName  string    `datastore:"kind_name"`
+	Timestamp time.Time `datastore:"timestamp"`
+}
+
+// CountResult is the result of CountUpTo and EstimateCount.
+// Capped means there are more than Count entities.
+// Approximate means Count is given by the statistics.
+type CountResult struct {
+	Count       int  `json:"count"`
+	Capped      bool `json:"capped,omitempty"`
+	Approximate bool `json:"approximate,omitempty"`
+}
+
+// String returns Count with "+" when it's capped, like "1000+".
+func (r *CountResult) String() string {
+	s := strconv.Itoa(r.Count)
+	if r.Capped {
+		s += "+"
+	}
+	return s
+}
+
+// KindStat returns the statistics of the kind in the namespace of e.
+func (e *Executor) KindStat(ctx context.Context) (*KindStat, error) {
+	kind := StatKind
+	if e.Namespace != "" {
+		kind = StatNsKind
+	}
+	q := datastore.NewQuery(kind).Filter("kind_name =", e.Kind).Limit(1)
+	if e.Namespace != "" {
+		q = q.Namespace(e.Namespace)
+	}
+	var stats []*KindStat
+	if _, err := e.Client.GetAll(ctx, q, &stats); err != nil {
+		// Statistics have more properties than KindStat
+		var mismatch *datastore.ErrFieldMismatch
+		if !errors.As(err, &mismatch) {
+			return nil, err
+		}
+	}
+	if len(stats) == 0 {
+		return nil, ErrNoKindStat
+	}
+	return stats[0], nil
+}
+
+// EstimateCount returns the number of all entities of the kind given by
+// the statistics. It returns ErrNoKindStat if they aren't available yet.
+func (e *Executor) EstimateCount(ctx context.Context) (*CountResult, error) {
+	stat, err := e.KindStat(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &CountResult{Count: int(stat.Count), Approximate: true}, nil
+}
+
+// CountUpTo counts the entities for qb but stops at n so that it costs
+// at most n+1 index entries. The result is Capped if there are more.
+// The statistics are used for qb without conditions if they show more
+// than n entities. Aggregation queries are used if the client supports.
+func (e *Executor) CountUpTo(ctx context.Context, qb *QueryBuilder, n int) (*CountResult, error) {
+	qb, err := e.Prepare(qb)
+	if err != nil {
+		return nil, err
+	}
+	return e.countUpTo(ctx, qb, n)
+}
+
+func (e *Executor) countUpTo(ctx context.Context, qb *QueryBuilder, n int) (*CountResult, error) {
+	var r *CountResult
+	err := e.Telemetry.observe(ctx, "querybuilder.CountUpTo", e, qb, func(ctx context.Context) (int, error) {
+		var err error
+		r, err = e.countUpToImpl(ctx, qb, n)
+		if err != nil {
+			return 0, err
+		}
+		return r.Count, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (e *Executor) countUpToImpl(ctx context.Context, qb *QueryBuilder, n int) (*CountResult, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("Invalid count limit: %d", n)
+	}
+	if client := qb.ClientConditions(); len(client) > 0 {
+		return nil, fmt.Errorf("Can't count with conditions evaluated on client: %v", client.Fields())
+	}
+	if qb.Geo != nil {
+		return nil, fmt.Errorf("Can't count with geo filter on %s", qb.Geo.Field)
+	}
+	if len(qb.Conditions) == 0 && len(qb.interceptors) == 0 {
+		stat, err := e.KindStat(ctx)
+		if err != nil && !errors.Is(err, ErrNoKindStat) {
+			return nil, err
+		}
+		if stat != nil && stat.Count > int64(n) {
+			return &CountResult{Count: n, Capped: true, Approximate: true}, nil
+		}
+	}
+	q := qb.BuildForCount(e.NewQuery()).Limit(n + 1)
+	c, err := e.countQuery(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if c > n {
+		return &CountResult{Count: n, Capped: true}, nil
+	}
+	return &CountResult{Count: c}, nil
+}
+
+func (e *Executor) countQuery(ctx context.Context, q *datastore.Query) (int, error) {
+	ac, ok := e.Client.(AggregationClient)
+	if !ok {
+		return e.Client.Count(ctx, q)
+	}
+	res, err := ac.RunAggregationQuery(ctx, q.NewAggregationQuery().WithCount(countAlias))
+	if err != nil {
+		return 0, err
+	}
+	switch v := res[countAlias].(type) {
+	case int64:
+		return int(v), nil
+	case interface{ GetIntegerValue() int64 }: // *datastorepb.Value
+		return int(v.GetIntegerValue()), nil
+	default:
+		return 0, fmt.Errorf("Unsupported count result %T", v)
+	}
+}
+
+// ConditionsFingerprint returns the hash of the conditions regardless of
+// their order, which is all that counts depend on. The values are
+// distinguished by their Datastore types, e.g. 1 and 1.0 aren't equal.
+func (qb *QueryBuilder) ConditionsFingerprint() (string, error) {
+	conds := make([]string, 0, len(qb.Conditions))
+	for _, c := range qb.Conditions {
+		b, err := json.Marshal([]interface{}{c, valueTypeName(c.Value)})
+		if err != nil {
+			return "", err
+		}
+		conds = append(conds, string(b))
+	}
+	sort.Strings(conds)
+	b, err := json.Marshal(conds)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// valueTypeName returns the name of the Datastore type of v.
+func valueTypeName(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	switch {
+	case !rv.IsValid():
+		return "null"
+	case isIntKind(rv.Kind()):
+		return "integer"
+	case rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64:
+		return "double"
+	default:
+		return rv.Type().String()
+	}
+}
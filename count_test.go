@@ -0,0 +1,172 @@
+package querybuilder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statClient struct {
+	*fakeClient
+	stats     []*KindStat
+	statCalls int
+}
+
+func (c *statClient) GetAll(ctx context.Context, q *datastore.Query, dst interface{}) ([]*datastore.Key, error) {
+	stats, ok := dst.(*[]*KindStat)
+	if !ok {
+		return c.fakeClient.GetAll(ctx, q, dst)
+	}
+	c.statCalls++
+	keys := []*datastore.Key{}
+	for _, s := range c.stats {
+		copied := *s
+		*stats = append(*stats, &copied)
+		keys = append(keys, datastore.NameKey(StatKind, s.KindName, nil))
+	}
+	return keys, nil
+}
+
+type aggregationClient struct {
+	*fakeClient
+	count int64
+	calls int
+}
+
+func (c *aggregationClient) RunAggregationQuery(ctx context.Context, aq *datastore.AggregationQuery) (datastore.AggregationResult, error) {
+	c.calls++
+	return datastore.AggregationResult{countAlias: c.count}, nil
+}
+
+func TestCountUpTo(t *testing.T) {
+	ctx := context.Background()
+	all := len(Entities)
+
+	{ // Statistics for the kind without conditions
+		cli := &statClient{fakeClient: &fakeClient{entities: Entities}, stats: []*KindStat{{Count: 1000, KindName: Kind4Test}}}
+		r, err := NewExecutor(cli, Kind4Test).CountUpTo(ctx, New(), 10)
+		assert.NoError(t, err)
+		assert.Equal(t, &CountResult{Count: 10, Capped: true, Approximate: true}, r)
+		assert.Equal(t, "10+", r.String())
+		assert.Equal(t, 1, cli.statCalls)
+		assert.Equal(t, 0, cli.countCalls)
+	}
+
+	{ // Statistics within the limit are counted exactly
+		cli := &statClient{fakeClient: &fakeClient{entities: Entities}, stats: []*KindStat{{Count: 5, KindName: Kind4Test}}}
+		r, err := NewExecutor(cli, Kind4Test).CountUpTo(ctx, New(), 100)
+		assert.NoError(t, err)
+		assert.Equal(t, &CountResult{Count: all}, r)
+		assert.Equal(t, 1, cli.countCalls)
+	}
+
+	{ // Without statistics
+		cli := &statClient{fakeClient: &fakeClient{entities: Entities}}
+		r, err := NewExecutor(cli, Kind4Test).CountUpTo(ctx, New(), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, &CountResult{Count: 2, Capped: true}, r)
+		assert.Equal(t, 1, cli.countCalls)
+	}
+
+	{ // Statistics aren't used with conditions
+		cli := &statClient{fakeClient: &fakeClient{entities: Entities}, stats: []*KindStat{{Count: 1000, KindName: Kind4Test}}}
+		r, err := NewExecutor(cli, Kind4Test).CountUpTo(ctx, New().Eq("Int2", 1), 100)
+		assert.NoError(t, err)
+		assert.Equal(t, &CountResult{Count: all}, r)
+		assert.Equal(t, "6", r.String())
+		assert.Equal(t, 0, cli.statCalls)
+	}
+
+	{ // Aggregation
+		cli := &aggregationClient{fakeClient: &fakeClient{entities: Entities}, count: 3}
+		e := NewExecutor(cli, Kind4Test)
+		r, err := e.CountUpTo(ctx, New().Gte("Int1", 4), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, &CountResult{Count: 2, Capped: true}, r)
+		r, err = e.CountUpTo(ctx, New().Gte("Int1", 4), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, &CountResult{Count: 3}, r)
+		assert.Equal(t, 2, cli.calls)
+		assert.Equal(t, 0, cli.countCalls)
+	}
+
+	{ // Invalid
+		e := NewExecutor(&fakeClient{}, Kind4Test)
+		_, err := e.CountUpTo(ctx, New().Eq("Int2", 1), 0)
+		assert.Error(t, err)
+		_, err = e.CountUpTo(ctx, New().Gte("Int1", 2).Lt("Int2", 5).EvaluateIneqOnClient(0), 10)
+		assert.Error(t, err)
+	}
+}
+
+func TestEstimateCount(t *testing.T) {
+	ctx := context.Background()
+
+	{
+		cli := &statClient{fakeClient: &fakeClient{}, stats: []*KindStat{{Count: 1234, KindName: Kind4Test}}}
+		r, err := (&Executor{Client: cli, Kind: Kind4Test, Namespace: "ns1"}).EstimateCount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, &CountResult{Count: 1234, Approximate: true}, r)
+	}
+
+	{
+		_, err := NewExecutor(&statClient{fakeClient: &fakeClient{}}, Kind4Test).EstimateCount(ctx)
+		assert.True(t, errors.Is(err, ErrNoKindStat))
+	}
+}
+
+func TestCachingExecutorCountUpTo(t *testing.T) {
+	ctx := context.Background()
+	cli := &fakeClient{entities: Entities}
+	e := NewCachingExecutor(NewExecutor(cli, Kind4Test), NewLRUCache(100), time.Minute)
+
+	{
+		r, err := e.CountUpTo(ctx, New().Eq("Int2", 1).Eq("Str1", "a"), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, "3+", r.String())
+		assert.Equal(t, 1, cli.countCalls)
+	}
+
+	{ // Same set of conditions in another order with a sort
+		r, err := e.CountUpTo(ctx, New().Eq("Str1", "a").Eq("Int2", 1).Desc("Int1"), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, &CountResult{Count: 3, Capped: true}, r)
+		assert.Equal(t, 1, cli.countCalls)
+	}
+
+	{ // Different limit
+		_, err := e.CountUpTo(ctx, New().Eq("Int2", 1).Eq("Str1", "a"), 100)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, cli.countCalls)
+	}
+
+	{ // Different conditions
+		_, err := e.CountUpTo(ctx, New().Eq("Int2", 2).Eq("Str1", "a"), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, cli.countCalls)
+	}
+
+	{ // Interceptors can change the conditions
+		rewrite := func(next BuildFunc) BuildFunc {
+			return func(mode BuildMode, qb *QueryBuilder, q *datastore.Query) (*datastore.Query, Assigners) {
+				return next(mode, qb.Eq("Int1", 1), q)
+			}
+		}
+		for i := 0; i < 2; i++ {
+			_, err := e.CountUpTo(ctx, New().Eq("Int2", 1).Eq("Str1", "a").Use(rewrite), 3)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 5, cli.countCalls)
+	}
+
+	{ // Integers and doubles are different in Datastore
+		_, err := e.CountUpTo(ctx, New().Eq("Int2", 2.0).Eq("Str1", "a"), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, cli.countCalls)
+	}
+}
@@ -0,0 +1,32 @@
+package querybuilder
+
+import (
+	"time"
+)
+
+var Now = time.Now
+
+func (qb *QueryBuilder) Between(field string, from, to interface{}) *QueryBuilder {
+	return qb.Gte(field, from).Lt(field, to)
+}
+
+func (qb *QueryBuilder) OnDate(field string, date time.Time, loc *time.Location) *QueryBuilder {
+	if loc == nil {
+		loc = date.Location()
+	}
+	d := date.In(loc)
+	from := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc)
+	return qb.Between(field, from, from.AddDate(0, 0, 1))
+}
+
+func (qb *QueryBuilder) InMonth(field string, year int, month time.Month, loc *time.Location) *QueryBuilder {
+	if loc == nil {
+		loc = time.UTC
+	}
+	from := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return qb.Between(field, from, from.AddDate(0, 1, 0))
+}
+
+func (qb *QueryBuilder) Since(field string, d time.Duration) *QueryBuilder {
+	return qb.Gte(field, Now().Add(-d))
+}
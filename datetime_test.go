@@ -0,0 +1,70 @@
+package querybuilder
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateTimeHelpers(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+
+	{
+		from := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		b := New().Between("CreatedAt", from, to)
+		assert.Equal(t, Conditions{{"CreatedAt", GTE, from}, {"CreatedAt", LT, to}}, b.Conditions)
+		assert.Equal(t, Strings{"CreatedAt"}, b.SortFields)
+	}
+
+	{
+		// 2019-12-01 20:00 UTC is 2019-12-02 05:00 in JST
+		b := New().OnDate("CreatedAt", time.Date(2019, 12, 1, 20, 0, 0, 0, time.UTC), jst)
+		if assert.Equal(t, 2, len(b.Conditions)) {
+			assert.True(t, time.Date(2019, 12, 1, 15, 0, 0, 0, time.UTC).Equal(b.Conditions[0].Value.(time.Time)))
+			assert.True(t, time.Date(2019, 12, 2, 15, 0, 0, 0, time.UTC).Equal(b.Conditions[1].Value.(time.Time)))
+		}
+	}
+
+	{
+		b := New().InMonth("CreatedAt", 2019, time.December, jst)
+		if assert.Equal(t, 2, len(b.Conditions)) {
+			assert.Equal(t, time.Date(2019, 12, 1, 0, 0, 0, 0, jst), b.Conditions[0].Value)
+			assert.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, jst), b.Conditions[1].Value)
+		}
+	}
+
+	{
+		orig := Now
+		defer func() { Now = orig }()
+		Now = func() time.Time { return time.Date(2019, 12, 1, 12, 0, 0, 0, time.UTC) }
+
+		b := New().Asc("Name").Since("CreatedAt", 36*time.Hour)
+		assert.Equal(t, Conditions{{"CreatedAt", GTE, time.Date(2019, 11, 30, 0, 0, 0, 0, time.UTC)}}, b.Conditions)
+		assert.Equal(t, Strings{"CreatedAt", "Name"}, b.SortFields)
+	}
+}
+
+func TestTimeValueSerialization(t *testing.T) {
+	at := time.Date(2019, 12, 1, 9, 30, 0, 123456000, time.FixedZone("JST", 9*60*60))
+	b := New("Name", "CreatedAt").Eq("CreatedAt", at).Gte("UpdatedAt", at).Eq("Name", "foo")
+	assert.Equal(t, at, b.Conditions[0].OriginalTypeValue())
+
+	bytes, err := json.Marshal(b)
+	assert.NoError(t, err)
+
+	var r QueryBuilder
+	assert.NoError(t, json.Unmarshal(bytes, &r))
+	if assert.Equal(t, 3, len(r.Conditions)) {
+		assert.True(t, at.Equal(r.Conditions[0].Value.(time.Time)))
+		assert.True(t, at.Equal(r.Conditions[1].OriginalTypeValue().(time.Time)))
+		assert.Equal(t, "foo", r.Conditions[2].Value)
+	}
+	if assert.Equal(t, 2, len(r.Assigns)) {
+		assert.True(t, at.Equal(r.Assigns[0].Value.(time.Time)))
+	}
+
+	assert.Error(t, json.Unmarshal([]byte(`{"conditions":[{"field":"A","ope":"=","value":1,"type":"time"}]}`), &r))
+}
@@ -0,0 +1,346 @@
+package querybuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/datastore"
+	"google.golang.org/api/iterator"
+)
+
+type Client interface {
+	GetAll(ctx context.Context, q *datastore.Query, dst interface{}) ([]*datastore.Key, error)
+	Count(ctx context.Context, q *datastore.Query) (int, error)
+}
+
+// RunClient is implemented by *datastore.Client. Executor reads results
+// batch by batch with cursors when its Client implements it, otherwise it
+// fetches the batches by GetAll with offsets.
+type RunClient interface {
+	Run(ctx context.Context, q *datastore.Query) *datastore.Iterator
+}
+
+type Runner interface {
+	GetAll(ctx context.Context, qb *QueryBuilder, dst interface{}) ([]*datastore.Key, error)
+	Count(ctx context.Context, qb *QueryBuilder) (int, error)
+}
+
+const DefaultBatchSize = 100
+
+// ErrMaxScanExceeded is returned by GetAll with the entities matched
+// before MaxScan entities are scanned, so that they can be shown.
+var ErrMaxScanExceeded = errors.New("Max scan exceeded")
+
+type Executor struct {
+	Client       Client
+	Kind         string
+	Namespace    string
+	Policies     []Policy
+	Interceptors []Interceptor
+	Telemetry    *Telemetry
+	// PageTokens requires cursors and offsets to be given by its tokens
+	// through Page if it's set.
+	PageTokens *PageTokenCodec
+}
+
+func NewExecutor(cli Client, kind string) *Executor {
+	return &Executor{Client: cli, Kind: kind}
+}
+
+func (e *Executor) NewQuery() *datastore.Query {
+	q := datastore.NewQuery(e.Kind)
+	if e.Namespace != "" {
+		q = q.Namespace(e.Namespace)
+	}
+	return q
+}
+
+// Prepare returns the builder which is actually run for qb.
+func (e *Executor) Prepare(qb *QueryBuilder) (*QueryBuilder, error) {
+	if params := qb.Params(); len(params) > 0 {
+		return nil, fmt.Errorf("Unbound params: %v", params)
+	}
+	if err := validateCursor(qb.Cursor); err != nil {
+		return nil, err
+	}
+	if offset, ok := qb.IntFilterValue("offset"); ok && offset < 0 {
+		return nil, fmt.Errorf("Invalid offset: %d", offset)
+	}
+	if e.PageTokens != nil && !qb.signedPaging {
+		if _, ok := qb.IntFilterValue("offset"); ok || qb.Cursor != "" {
+			return nil, ErrPageTokenUnsigned
+		}
+	}
+	if len(e.Policies) > 0 {
+		var err error
+		if qb, err = qb.ApplyPolicies(e.Policies...); err != nil {
+			return nil, err
+		}
+	}
+	if len(e.Interceptors) > 0 {
+		qb = qb.Clone().Use(e.Interceptors...)
+	}
+	return qb, nil
+}
+
+// Page returns the builder for the page given by pageToken, which must be
+// given by NextPageToken. Tokens are verified against qb with Policies
+// applied so that they can't be used for queries with other conditions.
+func (e *Executor) Page(qb *QueryBuilder, pageSize int, pageToken string) (*QueryBuilder, error) {
+	if e.PageTokens == nil {
+		return nil, fmt.Errorf("No PageTokens of the executor")
+	}
+	verified, err := qb.ApplyPolicies(e.Policies...)
+	if err != nil {
+		return nil, err
+	}
+	return e.PageTokens.apply(qb, verified, pageSize, pageToken)
+}
+
+// NextPageToken returns the token of the page which starts at cursor,
+// or at offset if cursor is nil.
+func (e *Executor) NextPageToken(qb *QueryBuilder, cursor *datastore.Cursor, offset int) (string, error) {
+	if e.PageTokens == nil {
+		return "", fmt.Errorf("No PageTokens of the executor")
+	}
+	verified, err := qb.ApplyPolicies(e.Policies...)
+	if err != nil {
+		return "", err
+	}
+	return e.PageTokens.Next(verified, cursor, offset)
+}
+
+func (e *Executor) GetAll(ctx context.Context, qb *QueryBuilder, dst interface{}) ([]*datastore.Key, error) {
+	qb, err := e.Prepare(qb)
+	if err != nil {
+		return nil, err
+	}
+	return e.getAll(ctx, qb, dst)
+}
+
+func (e *Executor) getAll(ctx context.Context, qb *QueryBuilder, dst interface{}) ([]*datastore.Key, error) {
+	var keys []*datastore.Key
+	err := e.Telemetry.observe(ctx, "querybuilder.GetAll", e, qb, func(ctx context.Context) (int, error) {
+		var err error
+		keys, err = e.getAllImpl(ctx, qb, dst)
+		return len(keys), err
+	})
+	return keys, err
+}
+
+func (e *Executor) getAllImpl(ctx context.Context, qb *QueryBuilder, dst interface{}) ([]*datastore.Key, error) {
+	if qb.Geo != nil {
+		return e.getAllNear(ctx, qb, dst)
+	}
+	if client := qb.ClientConditions(); len(client) > 0 {
+		return e.scan(ctx, qb, client, dst)
+	}
+	q, assigns := qb.Build(e.NewQuery())
+	keys, err := e.Client.GetAll(ctx, q, dst)
+	if err != nil {
+		return nil, err
+	}
+	return e.postProcess(ctx, qb, assigns, keys, dst)
+}
+
+func (e *Executor) Count(ctx context.Context, qb *QueryBuilder) (int, error) {
+	qb, err := e.Prepare(qb)
+	if err != nil {
+		return 0, err
+	}
+	return e.count(ctx, qb)
+}
+
+func (e *Executor) count(ctx context.Context, qb *QueryBuilder) (int, error) {
+	var c int
+	err := e.Telemetry.observe(ctx, "querybuilder.Count", e, qb, func(ctx context.Context) (int, error) {
+		var err error
+		c, err = e.countImpl(ctx, qb)
+		return c, err
+	})
+	return c, err
+}
+
+func (e *Executor) countImpl(ctx context.Context, qb *QueryBuilder) (int, error) {
+	if client := qb.ClientConditions(); len(client) > 0 {
+		return 0, fmt.Errorf("Can't count with conditions evaluated on client: %v", client.Fields())
+	}
+	if qb.Geo != nil {
+		return 0, fmt.Errorf("Can't count with geo filter on %s", qb.Geo.Field)
+	}
+	return e.Client.Count(ctx, qb.BuildForCount(e.NewQuery()))
+}
+
+func (e *Executor) scan(ctx context.Context, qb *QueryBuilder, client Conditions, dst interface{}) ([]*datastore.Key, error) {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Unsupported type of destination %T", dst)
+	}
+	dv = dv.Elem()
+
+	offset, _ := qb.IntFilterValue("offset")
+	limit, _ := qb.IntFilterValue("limit")
+	batchSize := qb.BatchSize
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+	base, assigns := qb.WithoutFilters().Build(e.NewQuery())
+	r := newBatchReader(e.Client, base, 0, -1)
+
+	keys := []*datastore.Key{}
+	skipped := 0
+	for !r.done {
+		size := batchSize
+		if qb.MaxScan > 0 {
+			if r.read >= qb.MaxScan {
+				// The entities matched so far are returned with the error
+				keys, err := e.postProcess(ctx, qb, assigns, keys, dst)
+				if err != nil {
+					return nil, err
+				}
+				return keys, fmt.Errorf("%w: scanned %d entities", ErrMaxScanExceeded, r.read)
+			}
+			if rest := qb.MaxScan - r.read; rest < size {
+				size = rest
+			}
+		}
+		page := reflect.New(dv.Type())
+		pageKeys, err := r.next(ctx, size, page.Interface())
+		if err != nil {
+			return nil, err
+		}
+		for i, key := range pageKeys {
+			entity := page.Elem().Index(i)
+			ok, err := client.Match(entity.Interface())
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if skipped < offset {
+				skipped++
+				continue
+			}
+			dv.Set(reflect.Append(dv, entity))
+			keys = append(keys, key)
+			if limit > 0 && len(keys) >= limit {
+				return e.postProcess(ctx, qb, assigns, keys, dst)
+			}
+		}
+	}
+	return e.postProcess(ctx, qb, assigns, keys, dst)
+}
+
+func (e *Executor) postProcess(ctx context.Context, qb *QueryBuilder, assigns Assigners, keys []*datastore.Key, dst interface{}) ([]*datastore.Key, error) {
+	var r []*datastore.Key
+	err := e.Telemetry.span(ctx, "querybuilder.Assign", e, qb, func(ctx context.Context) (int, error) {
+		var err error
+		r, err = qb.postProcess(keys, dst, assigns)
+		return len(r), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// batchReader reads the results of a query batch by batch. Each batch
+// starts at the cursor where the previous one ended, so that Datastore
+// doesn't read the entities of the former batches again to skip them.
+type batchReader struct {
+	client Client
+	query  *datastore.Query
+	offset int
+	limit  int // no limit if negative
+	cursor *datastore.Cursor
+	read   int
+	done   bool
+}
+
+func newBatchReader(cli Client, q *datastore.Query, offset, limit int) *batchReader {
+	return &batchReader{client: cli, query: q, offset: offset, limit: limit}
+}
+
+// next reads up to size results into dst, which is a pointer to a slice
+// or nil for keys only queries. done is set after the last batch.
+func (r *batchReader) next(ctx context.Context, size int, dst interface{}) ([]*datastore.Key, error) {
+	if r.limit >= 0 && r.limit-r.read < size {
+		size = r.limit - r.read
+	}
+	if r.done || size < 1 {
+		r.done = true
+		return []*datastore.Key{}, nil
+	}
+	var keys []*datastore.Key
+	var err error
+	if rc, ok := r.client.(RunClient); ok {
+		keys, err = r.run(ctx, rc, size, dst)
+	} else {
+		keys, err = r.client.GetAll(ctx, r.query.Offset(r.offset+r.read).Limit(size), dst)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.read += len(keys)
+	if len(keys) < size || (r.limit >= 0 && r.read >= r.limit) {
+		r.done = true
+	}
+	return keys, nil
+}
+
+func (r *batchReader) run(ctx context.Context, rc RunClient, size int, dst interface{}) ([]*datastore.Key, error) {
+	q := r.query.Limit(size)
+	if r.cursor != nil {
+		q = q.Start(*r.cursor)
+	} else if r.offset > 0 {
+		q = q.Offset(r.offset)
+	}
+	var dv reflect.Value
+	if dst != nil {
+		dv = reflect.ValueOf(dst)
+		if dv.Kind() != reflect.Ptr || dv.Elem().Kind() != reflect.Slice {
+			return nil, fmt.Errorf("Unsupported type of destination %T", dst)
+		}
+		dv = dv.Elem()
+	}
+	it := rc.Run(ctx, q)
+	keys := []*datastore.Key{}
+	for {
+		var entity reflect.Value
+		var ptr interface{}
+		if dst != nil {
+			entity, ptr = newElem(dv.Type().Elem())
+		}
+		key, err := it.Next(ptr)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		if dst != nil {
+			dv.Set(reflect.Append(dv, entity))
+		}
+	}
+	c, err := it.Cursor()
+	if err != nil {
+		return nil, err
+	}
+	r.cursor = &c
+	return keys, nil
+}
+
+// newElem returns a new element of a slice of elemType and the pointer
+// which Datastore loads an entity into.
+func newElem(elemType reflect.Type) (reflect.Value, interface{}) {
+	if elemType.Kind() == reflect.Ptr {
+		v := reflect.New(elemType.Elem())
+		return v, v.Interface()
+	}
+	v := reflect.New(elemType)
+	return v.Elem(), v.Interface()
+}
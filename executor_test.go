@@ -0,0 +1,167 @@
+package querybuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClient struct {
+	entities    interface{} // slice of pointers to entities
+	keyIDs      []int64     // IDs of keys for entities. Indexes are used when it's empty
+	pageSize    int         // caps the number of entities of each call when positive
+	getAllCalls int
+	countCalls  int
+}
+
+func (c *fakeClient) GetAll(ctx context.Context, q *datastore.Query, dst interface{}) ([]*datastore.Key, error) {
+	c.getAllCalls++
+	keys := []*datastore.Key{}
+	if c.entities == nil {
+		return keys, nil
+	}
+	src := reflect.ValueOf(c.entities)
+	dv := reflect.ValueOf(dst)
+	if dst == nil { // keys only
+		dv = reflect.New(src.Type())
+	}
+	if dv.Kind() != reflect.Ptr || dv.Elem().Type() != src.Type() {
+		return nil, fmt.Errorf("Unsupported type of destination %T", dst)
+	}
+	offset, limit := queryPaging(q)
+	start, end := offset, src.Len()
+	if start > end {
+		start = end
+	}
+	if limit >= 0 && start+limit < end {
+		end = start + limit
+	}
+	if c.pageSize > 0 && start+c.pageSize < end {
+		end = start + c.pageSize
+	}
+	for i := start; i < end; i++ {
+		copied := reflect.New(src.Type().Elem().Elem())
+		copied.Elem().Set(src.Index(i).Elem())
+		dv.Elem().Set(reflect.Append(dv.Elem(), copied))
+		id := int64(i + 1)
+		if len(c.keyIDs) > 0 {
+			id = c.keyIDs[i]
+		}
+		keys = append(keys, datastore.IDKey(Kind4Test, id, nil))
+	}
+	return keys, nil
+}
+
+// queryPaging returns the offset and the limit of q. The limit is
+// negative if it's not set.
+func queryPaging(q *datastore.Query) (int, int) {
+	v := reflect.ValueOf(q).Elem()
+	return int(v.FieldByName("offset").Int()), int(v.FieldByName("limit").Int())
+}
+
+func (c *fakeClient) Count(ctx context.Context, q *datastore.Query) (int, error) {
+	c.countCalls++
+	if c.entities == nil {
+		return 0, nil
+	}
+	return reflect.ValueOf(c.entities).Len(), nil
+}
+
+func TestExecutorWithClientIneq(t *testing.T) {
+	ctx := context.Background()
+
+	int1s := func(entities []*Entity4Test) []int {
+		r := []int{}
+		for _, e := range entities {
+			r = append(r, e.Int1)
+		}
+		return r
+	}
+
+	{
+		b := New().Gte("Int1", 2).Lt("Int2", 5).Limit(2)
+		assert.True(t, b.Conditions.HasMultipleIneqFields())
+		b.EvaluateIneqOnClient(0)
+		b.BatchSize = 2
+		assert.Equal(t, Strings{"Int2", "Int1"}, b.SortFields)
+		assert.Equal(t, "Int2", b.ServerIneqField())
+		assert.Equal(t, Conditions{{"Int2", LT, 5}}, b.ServerConditions())
+		assert.Equal(t, Conditions{{"Int1", GTE, 2}}, b.ClientConditions())
+
+		cli := &fakeClient{entities: Entities, pageSize: 2}
+		var entities []*Entity4Test
+		keys, err := NewExecutor(cli, Kind4Test).GetAll(ctx, b, &entities)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(keys))
+		assert.Equal(t, []int{2, 3}, int1s(entities))
+		assert.Equal(t, 2, cli.getAllCalls)
+	}
+
+	{ // Offset and projection
+		b := New("Str1").Gte("Int1", 2).Lt("Int2", 5).Offset(1).Limit(2).EvaluateIneqOnClient(0)
+		b.BatchSize = 4
+		assert.Equal(t, Strings{"Str1", "Int1"}, b.ProjectFields())
+
+		cli := &fakeClient{entities: Entities, pageSize: 4}
+		var entities []*Entity4Test
+		_, err := NewExecutor(cli, Kind4Test).GetAll(ctx, b, &entities)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3, 4}, int1s(entities))
+		assert.Equal(t, 1, cli.getAllCalls)
+	}
+
+	{ // Without limit
+		b := New().Gt("Int1", 4).Gt("Int2", 0).EvaluateIneqOnClient(0)
+		b.BatchSize = 4
+		cli := &fakeClient{entities: Entities, pageSize: 4}
+		var entities []*Entity4Test
+		_, err := NewExecutor(cli, Kind4Test).GetAll(ctx, b, &entities)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{5, 6}, int1s(entities))
+		assert.Equal(t, 2, cli.getAllCalls)
+	}
+
+	{ // Offset over batches
+		b := New().Gte("Int1", 2).Lt("Int2", 100).Offset(2).EvaluateIneqOnClient(0)
+		b.BatchSize = 2
+		cli := &fakeClient{entities: Entities}
+		var entities []*Entity4Test
+		_, err := NewExecutor(cli, Kind4Test).GetAll(ctx, b, &entities)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{4, 5, 6}, int1s(entities))
+		assert.Equal(t, 4, cli.getAllCalls)
+	}
+
+	{ // Max scan
+		b := New().Gte("Int1", 6).Lt("Int2", 100).Limit(1).EvaluateIneqOnClient(3)
+		b.BatchSize = 2
+		cli := &fakeClient{entities: Entities}
+		var entities []*Entity4Test
+		_, err := NewExecutor(cli, Kind4Test).GetAll(ctx, b, &entities)
+		assert.True(t, errors.Is(err, ErrMaxScanExceeded))
+		assert.Equal(t, 2, cli.getAllCalls)
+	}
+
+	{ // Entities matched before max scan are returned with the error
+		b := New().Gte("Int1", 2).Lt("Int2", 100).Limit(5).EvaluateIneqOnClient(3)
+		b.BatchSize = 2
+		cli := &fakeClient{entities: Entities}
+		var entities []*Entity4Test
+		keys, err := NewExecutor(cli, Kind4Test).GetAll(ctx, b, &entities)
+		assert.True(t, errors.Is(err, ErrMaxScanExceeded))
+		assert.Equal(t, []int{2, 3}, int1s(entities))
+		assert.Equal(t, 2, len(keys))
+	}
+
+	{ // Count
+		b := New().Gte("Int1", 2).Lt("Int2", 5).EvaluateIneqOnClient(0)
+		_, err := NewExecutor(&fakeClient{}, Kind4Test).Count(ctx, b)
+		assert.Error(t, err)
+	}
+}
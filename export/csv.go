@@ -0,0 +1,52 @@
+//go:build go1.23
+
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// CSVWriter writes a header line of the column names. Values of
+// multi-valued columns are written as JSON arrays.
+type CSVWriter struct {
+	w *csv.Writer
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (w *CSVWriter) WriteHeader(columns []*Column) error {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return w.w.Write(names)
+}
+
+func (w *CSVWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		if vs, ok := v.([]interface{}); ok {
+			b, err := json.Marshal(vs)
+			if err != nil {
+				return err
+			}
+			record[i] = string(b)
+			continue
+		}
+		record[i] = formatValue(v)
+	}
+	return w.w.Write(record)
+}
+
+func (w *CSVWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *CSVWriter) Close() error {
+	return w.Flush()
+}
@@ -0,0 +1,271 @@
+//go:build go1.23
+
+// Package export writes entities matching a QueryBuilder as CSV, JSON Lines
+// or Parquet. Entities are fetched page by page so that the memory usage
+// doesn't depend on the number of the results.
+package export
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/akm/querybuilder"
+)
+
+type Format string
+
+const (
+	CSV     Format = "csv"
+	JSONL   Format = "jsonl"
+	Parquet Format = "parquet"
+)
+
+// RowWriter writes rows of values. A value is nil, int64, float64, string,
+// bool, time.Time or []byte, or a []interface{} of them for a multi-valued
+// column.
+type RowWriter interface {
+	WriteHeader(columns []*Column) error
+	WriteRow(values []interface{}) error
+	// Flush is called after each page.
+	Flush() error
+	Close() error
+}
+
+func NewWriter(format Format, w io.Writer) (RowWriter, error) {
+	switch format {
+	case CSV:
+		return NewCSVWriter(w), nil
+	case JSONL:
+		return NewJSONLWriter(w), nil
+	case Parquet:
+		return NewParquetWriter(w), nil
+	default:
+		return nil, fmt.Errorf("Unknown export format: %s", format)
+	}
+}
+
+// Export writes the entities of T matching qb to w and returns the number
+// of written rows. DefaultBatchSize is used if pageSize is not positive.
+func Export[T any](ctx context.Context, e *querybuilder.Executor, qb *querybuilder.QueryBuilder, w RowWriter, pageSize int) (int, error) {
+	columns, err := Columns(reflect.TypeOf((*T)(nil)).Elem(), qb)
+	if err != nil {
+		return 0, err
+	}
+	if err := w.WriteHeader(columns); err != nil {
+		return 0, err
+	}
+	count := 0
+	for page, err := range querybuilder.Pages[T](ctx, e, qb, pageSize) {
+		if err != nil {
+			return count, err
+		}
+		for _, entity := range page {
+			values, err := Row(columns, entity)
+			if err != nil {
+				return count, err
+			}
+			if err := w.WriteRow(values); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if err := w.Flush(); err != nil {
+			return count, err
+		}
+	}
+	return count, w.Close()
+}
+
+type Column struct {
+	Name     string       // property name
+	Path     string       // path of the struct field
+	Type     reflect.Type // type of the values
+	Multiple bool         // true if the path goes through a slice
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	geoPointType = reflect.TypeOf(datastore.GeoPoint{})
+	keyType      = reflect.TypeOf(&datastore.Key{})
+)
+
+// Columns returns the columns for ProjectFields of qb, or all the
+// properties of t except Ignored fields if qb doesn't project.
+// Properties of nested structs are flattened to dot separated names.
+func Columns(t reflect.Type, qb *querybuilder.QueryBuilder) ([]*Column, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	names := qb.ProjectFields()
+	if len(names) == 0 {
+		names = properties(t, "").Except(qb.Ignored)
+	}
+	r := make([]*Column, 0, len(names))
+	for _, name := range names {
+		path, typ, err := querybuilder.ResolveField(t, name)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, &Column{Name: name, Path: path, Type: typ, Multiple: multiple(t, path)})
+	}
+	return r, nil
+}
+
+func properties(t reflect.Type, prefix string) querybuilder.Strings {
+	r := querybuilder.Strings{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("datastore"); ok {
+			n := strings.Split(tag, ",")[0]
+			if n == "-" {
+				continue
+			}
+			if n != "" {
+				name = n
+			}
+		}
+		ft := elemType(sf.Type)
+		nested := ft.Kind() == reflect.Struct && ft != timeType && ft != geoPointType
+		switch {
+		case nested && sf.Anonymous:
+			r = append(r, properties(ft, prefix)...)
+		case nested:
+			r = append(r, properties(ft, prefix+name+".")...)
+		default:
+			r = append(r, prefix+name)
+		}
+	}
+	return r
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	for {
+		switch {
+		case t.Kind() == reflect.Ptr && t != keyType:
+			t = t.Elem()
+		case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+func multiple(t reflect.Type, path string) bool {
+	for _, name := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+			return true
+		}
+		sf, ok := t.FieldByName(name)
+		if !ok {
+			return false
+		}
+		t = sf.Type
+	}
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+// Row returns the values of the columns in entity.
+func Row(columns []*Column, entity interface{}) ([]interface{}, error) {
+	base := reflect.ValueOf(entity)
+	r := make([]interface{}, len(columns))
+	for i, c := range columns {
+		values := []interface{}{}
+		err := querybuilder.ReflectWalkIn(&base, c.Path, ".", func(v *reflect.Value) error {
+			if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+				for j := 0; j < v.Len(); j++ {
+					values = append(values, Value(v.Index(j)))
+				}
+				return nil
+			}
+			values = append(values, Value(*v))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case c.Multiple:
+			r[i] = values
+		case len(values) > 0:
+			r[i] = values[0]
+		}
+	}
+	return r, nil
+}
+
+// Value converts v to one of the types RowWriter accepts.
+// Keys are encoded and GeoPoints are formatted as "lat,lng".
+func Value(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr && v.Type() != keyType {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Type() {
+	case keyType:
+		if v.IsNil() {
+			return nil
+		}
+		return v.Interface().(*datastore.Key).Encode()
+	case timeType:
+		return v.Interface().(time.Time)
+	case geoPointType:
+		g := v.Interface().(datastore.GeoPoint)
+		return strconv.FormatFloat(g.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(g.Lng, 'f', -1, 64)
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes()
+		}
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+func formatValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case string:
+		return x
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(x)
+	default:
+		return fmt.Sprintf("%v", x)
+	}
+}
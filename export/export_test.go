@@ -0,0 +1,158 @@
+//go:build go1.23
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akm/querybuilder"
+)
+
+type Sub4Test struct {
+	I1 int
+	S1 string
+}
+
+type Entity4Test struct {
+	ID        int    `datastore:"id"`
+	Name      string `datastore:"name"`
+	Secret    string `datastore:"-"`
+	Tags      []string
+	Sub1      Sub4Test
+	Subs      []Sub4Test
+	CreatedAt time.Time
+}
+
+const Kind4Test = "entity4test"
+
+var createdAt = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var Entities = []*Entity4Test{
+	{ID: 1, Name: "foo", Tags: []string{"a", "b"}, Sub1: Sub4Test{I1: 10, S1: "x"}, Subs: []Sub4Test{{I1: 1}, {I1: 2}}, CreatedAt: createdAt},
+	{ID: 2, Name: "bar, baz", Sub1: Sub4Test{I1: 20, S1: "y"}, CreatedAt: createdAt},
+}
+
+type fakeClient struct {
+	pageSize    int // serves entities page by page when positive
+	getAllCalls int
+}
+
+func (c *fakeClient) GetAll(ctx context.Context, q *datastore.Query, dst interface{}) ([]*datastore.Key, error) {
+	c.getAllCalls++
+	keys := []*datastore.Key{}
+	dv := reflect.ValueOf(dst).Elem()
+	start, end := 0, len(Entities)
+	if c.pageSize > 0 {
+		start = min((c.getAllCalls-1)*c.pageSize, end)
+		end = min(start+c.pageSize, end)
+	}
+	for i := start; i < end; i++ {
+		copied := *Entities[i]
+		dv.Set(reflect.Append(dv, reflect.ValueOf(&copied)))
+		keys = append(keys, datastore.IDKey(Kind4Test, int64(i+1), nil))
+	}
+	return keys, nil
+}
+
+func (c *fakeClient) Count(ctx context.Context, q *datastore.Query) (int, error) {
+	return len(Entities), nil
+}
+
+func TestColumns(t *testing.T) {
+	typ := reflect.TypeOf(Entity4Test{})
+	{
+		columns, err := Columns(typ, querybuilder.New())
+		assert.NoError(t, err)
+		names := []string{}
+		for _, c := range columns {
+			names = append(names, c.Name)
+		}
+		assert.Equal(t, []string{"id", "name", "Tags", "Sub1.I1", "Sub1.S1", "Subs.I1", "Subs.S1", "CreatedAt"}, names)
+		assert.Equal(t, &Column{Name: "Subs.I1", Path: "Subs.I1", Type: reflect.TypeOf(0), Multiple: true}, columns[5])
+		assert.Equal(t, &Column{Name: "id", Path: "ID", Type: reflect.TypeOf(0)}, columns[0])
+	}
+	{ // Projection
+		columns, err := Columns(typ, querybuilder.New("name", "Sub1.I1"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(columns))
+		assert.Equal(t, "Sub1.I1", columns[1].Path)
+	}
+	{ // Ignored
+		qb := querybuilder.New()
+		qb.Ignored = querybuilder.Strings{"Tags", "Subs.I1", "Subs.S1"}
+		columns, err := Columns(typ, qb)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, len(columns))
+	}
+	{
+		_, err := Columns(typ, querybuilder.New("Unknown"))
+		assert.Error(t, err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+
+	{
+		var buf bytes.Buffer
+		qb := querybuilder.New("id", "name", "Tags", "Subs.I1", "CreatedAt")
+		e := querybuilder.NewExecutor(&fakeClient{}, Kind4Test)
+		n, err := Export[Entity4Test](ctx, e, qb, NewCSVWriter(&buf), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, "id,name,Tags,Subs.I1,CreatedAt\n"+
+			"1,foo,\"[\"\"a\"\",\"\"b\"\"]\",\"[1,2]\",2020-01-02T03:04:05Z\n"+
+			"2,\"bar, baz\",[],[],2020-01-02T03:04:05Z\n", buf.String())
+	}
+
+	{
+		var buf bytes.Buffer
+		qb := querybuilder.New("name", "Sub1.I1", "Tags").Eq("Sub1.S1", "x")
+		cli := &fakeClient{pageSize: 1}
+		e := querybuilder.NewExecutor(cli, Kind4Test)
+		n, err := Export[Entity4Test](ctx, e, qb, NewJSONLWriter(&buf), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, 3, cli.getAllCalls)
+		assert.Equal(t, `{"name":"foo","Sub1.I1":10,"Tags":["a","b"]}`+"\n"+
+			`{"name":"bar, baz","Sub1.I1":20,"Tags":[]}`+"\n", buf.String())
+	}
+
+	{
+		var buf bytes.Buffer
+		qb := querybuilder.New("id", "name", "Tags", "CreatedAt")
+		w, err := NewWriter(Parquet, &buf)
+		assert.NoError(t, err)
+		e := querybuilder.NewExecutor(&fakeClient{}, Kind4Test)
+		n, err := Export[Entity4Test](ctx, e, qb, w, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		type row struct {
+			ID        int64     `parquet:"id,optional"`
+			Name      string    `parquet:"name,optional"`
+			Tags      []string  `parquet:"Tags"`
+			CreatedAt time.Time `parquet:"CreatedAt,optional,timestamp(microsecond)"`
+		}
+		rows, err := parquet.Read[row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.NoError(t, err)
+		assert.Equal(t, []row{
+			{ID: 1, Name: "foo", Tags: []string{"a", "b"}, CreatedAt: createdAt},
+			{ID: 2, Name: "bar, baz", Tags: []string{}, CreatedAt: createdAt},
+		}, rows)
+	}
+
+	{
+		_, err := NewWriter(Format("xml"), &bytes.Buffer{})
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,55 @@
+//go:build go1.23
+
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// JSONLWriter writes a JSON object per line. The keys of the object are the
+// names of the columns in the order of the columns.
+type JSONLWriter struct {
+	w       *bufio.Writer
+	columns []*Column
+}
+
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *JSONLWriter) WriteHeader(columns []*Column) error {
+	w.columns = columns
+	return nil
+}
+
+func (w *JSONLWriter) WriteRow(values []interface{}) error {
+	w.w.WriteByte('{')
+	for i, c := range w.columns {
+		if i > 0 {
+			w.w.WriteByte(',')
+		}
+		k, err := json.Marshal(c.Name)
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		w.w.Write(k)
+		w.w.WriteByte(':')
+		w.w.Write(v)
+	}
+	w.w.WriteByte('}')
+	return w.w.WriteByte('\n')
+}
+
+func (w *JSONLWriter) Flush() error {
+	return w.w.Flush()
+}
+
+func (w *JSONLWriter) Close() error {
+	return w.Flush()
+}
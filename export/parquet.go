@@ -0,0 +1,85 @@
+//go:build go1.23
+
+package export
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// DefaultRowGroupSize is the max number of rows buffered by ParquetWriter.
+const DefaultRowGroupSize = 10000
+
+// ParquetWriter writes the columns of the property names. Multi-valued
+// columns are repeated and the others are optional.
+type ParquetWriter struct {
+	RowGroupSize int
+
+	output  io.Writer
+	w       *parquet.Writer
+	columns []*Column
+}
+
+func NewParquetWriter(w io.Writer) *ParquetWriter {
+	return &ParquetWriter{RowGroupSize: DefaultRowGroupSize, output: w}
+}
+
+func (w *ParquetWriter) WriteHeader(columns []*Column) error {
+	w.columns = columns
+	group := parquet.Group{}
+	for _, c := range columns {
+		node := parquetNode(c.Type)
+		if c.Multiple {
+			node = parquet.Repeated(node)
+		} else {
+			node = parquet.Optional(node)
+		}
+		group[c.Name] = node
+	}
+	w.w = parquet.NewWriter(w.output,
+		parquet.NewSchema("entity", group),
+		parquet.MaxRowsPerRowGroup(int64(w.RowGroupSize)),
+	)
+	return nil
+}
+
+func parquetNode(t reflect.Type) parquet.Node {
+	switch t {
+	case keyType, geoPointType:
+		return parquet.String()
+	case timeType:
+		return parquet.Timestamp(parquet.Microsecond)
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return parquet.Int(64)
+	case reflect.Float32, reflect.Float64:
+		return parquet.Leaf(parquet.DoubleType)
+	case reflect.Bool:
+		return parquet.Leaf(parquet.BooleanType)
+	case reflect.Slice:
+		return parquet.Leaf(parquet.ByteArrayType)
+	default:
+		return parquet.String()
+	}
+}
+
+func (w *ParquetWriter) WriteRow(values []interface{}) error {
+	row := make(map[string]interface{}, len(values))
+	for i, c := range w.columns {
+		row[c.Name] = values[i]
+	}
+	return w.w.Write(row)
+}
+
+// Flush does nothing because rows are buffered until the row group is full.
+func (w *ParquetWriter) Flush() error {
+	return nil
+}
+
+func (w *ParquetWriter) Close() error {
+	return w.w.Close()
+}
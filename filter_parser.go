@@ -0,0 +1,323 @@
+package querybuilder
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterParser parses filters of AIP-160 (https://google.aip.dev/160) into
+// conditions of QueryBuilder. Restrictions are combined by AND or by
+// whitespaces. OR, NOT and != are rejected because Datastore queries built
+// by QueryBuilder don't support them.
+//
+// `:` works like `=`, which matches any value of a multi-valued property.
+// A string value ending with `*` makes Starts with the prefix.
+type FilterParser struct {
+	// Allowed is the list of the fields which can be used in filters.
+	// All the fields are allowed if it's empty.
+	Allowed Strings
+	// Type is the type of the entities. If it's given, the fields are
+	// resolved by ResolveField and the values are converted to the types of
+	// the fields. Strings are parsed as RFC 3339 for time.Time fields.
+	Type reflect.Type
+}
+
+func ParseFilter(filter string, allowed ...string) (*QueryBuilder, error) {
+	p := &FilterParser{Allowed: allowed}
+	return p.Parse(filter)
+}
+
+func (p *FilterParser) Parse(filter string) (*QueryBuilder, error) {
+	return p.Apply(New(), filter)
+}
+
+// Apply adds the conditions of filter to qb.
+func (p *FilterParser) Apply(qb *QueryBuilder, filter string) (*QueryBuilder, error) {
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	ps := &filterParsing{parser: p, tokens: tokens, qb: qb}
+	if err := ps.expression(0); err != nil {
+		return nil, err
+	}
+	if t := ps.peek(); t.kind != filterEOF {
+		return nil, &FilterError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s", t)}
+	}
+	return qb, nil
+}
+
+// FilterError is an error of a filter. Pos is the byte offset in the filter.
+type FilterError struct {
+	Pos int
+	Msg string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("Invalid filter at %d: %s", e.Pos, e.Msg)
+}
+
+type filterTokenKind int
+
+const (
+	filterEOF filterTokenKind = iota
+	filterText
+	filterString
+	filterComparator
+	filterLParen
+	filterRParen
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	pos  int
+}
+
+func (t *filterToken) String() string {
+	if t.kind == filterEOF {
+		return "end of filter"
+	}
+	return strconv.Quote(t.text)
+}
+
+func (t *filterToken) keyword(s string) bool {
+	return t.kind == filterText && t.text == s
+}
+
+var filterComparators = []string{"<=", ">=", "!=", "<", ">", "=", ":"}
+
+func tokenizeFilter(s string) ([]*filterToken, error) {
+	r := []*filterToken{}
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			r = append(r, &filterToken{kind: filterLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			r = append(r, &filterToken{kind: filterRParen, text: ")", pos: i})
+			i++
+		case c == '"' || c == '\'':
+			start := i
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(s) {
+					return nil, &FilterError{Pos: start, Msg: "unterminated string"}
+				}
+				if s[i] == c {
+					i++
+					break
+				}
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+				i++
+			}
+			r = append(r, &filterToken{kind: filterString, text: b.String(), pos: start})
+		case strings.IndexByte("<>=!:", c) >= 0:
+			found := false
+			for _, cmp := range filterComparators {
+				if strings.HasPrefix(s[i:], cmp) {
+					r = append(r, &filterToken{kind: filterComparator, text: cmp, pos: i})
+					i += len(cmp)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, &FilterError{Pos: i, Msg: fmt.Sprintf("unexpected %q", c)}
+			}
+		default:
+			start := i
+			for i < len(s) && strings.IndexByte(" \t\n\r()\"'<>=!:", s[i]) < 0 {
+				i++
+			}
+			r = append(r, &filterToken{kind: filterText, text: s[start:i], pos: start})
+		}
+	}
+	return append(r, &filterToken{kind: filterEOF, pos: len(s)}), nil
+}
+
+type filterParsing struct {
+	parser *FilterParser
+	tokens []*filterToken
+	index  int
+	qb     *QueryBuilder
+}
+
+func (ps *filterParsing) peek() *filterToken {
+	return ps.tokens[ps.index]
+}
+
+func (ps *filterParsing) next() *filterToken {
+	t := ps.tokens[ps.index]
+	if t.kind != filterEOF {
+		ps.index++
+	}
+	return t
+}
+
+// expression parses restrictions until the end or a closing parenthesis
+// at depth.
+func (ps *filterParsing) expression(depth int) error {
+	first := true
+	for {
+		t := ps.peek()
+		switch {
+		case t.kind == filterEOF:
+			if first && depth > 0 {
+				return &FilterError{Pos: t.pos, Msg: "expression expected"}
+			}
+			return nil
+		case t.kind == filterRParen:
+			if depth == 0 {
+				return &FilterError{Pos: t.pos, Msg: "unexpected \")\""}
+			}
+			if first {
+				return &FilterError{Pos: t.pos, Msg: "expression expected"}
+			}
+			return nil
+		case t.keyword("AND"):
+			if first {
+				return &FilterError{Pos: t.pos, Msg: "unexpected AND"}
+			}
+			ps.next()
+			if n := ps.peek(); n.kind == filterEOF || n.kind == filterRParen || n.keyword("AND") {
+				return &FilterError{Pos: n.pos, Msg: fmt.Sprintf("restriction expected but %s", n)}
+			}
+			continue
+		case t.keyword("OR"):
+			return &FilterError{Pos: t.pos, Msg: "OR is not supported"}
+		case t.keyword("NOT") || (t.kind == filterText && strings.HasPrefix(t.text, "-") && !isFilterNumber(t.text)):
+			return &FilterError{Pos: t.pos, Msg: "negation is not supported"}
+		case t.kind == filterLParen:
+			ps.next()
+			if err := ps.expression(depth + 1); err != nil {
+				return err
+			}
+			if n := ps.next(); n.kind != filterRParen {
+				return &FilterError{Pos: n.pos, Msg: fmt.Sprintf("\")\" expected but %s", n)}
+			}
+		default:
+			if err := ps.restriction(); err != nil {
+				return err
+			}
+		}
+		first = false
+	}
+}
+
+var filterFieldPattern = regexp.MustCompile(`\A[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*\z`)
+
+func (ps *filterParsing) restriction() error {
+	field := ps.next()
+	if field.kind != filterText || !filterFieldPattern.MatchString(field.text) {
+		return &FilterError{Pos: field.pos, Msg: fmt.Sprintf("field expected but %s", field)}
+	}
+	if len(ps.parser.Allowed) > 0 && !ps.parser.Allowed.Has(field.text) {
+		return &FilterError{Pos: field.pos, Msg: fmt.Sprintf("field %s is not allowed", field.text)}
+	}
+	cmp := ps.next()
+	if cmp.kind != filterComparator {
+		return &FilterError{Pos: cmp.pos, Msg: fmt.Sprintf("comparator expected but %s", cmp)}
+	}
+	arg := ps.next()
+	if arg.kind != filterText && arg.kind != filterString {
+		return &FilterError{Pos: arg.pos, Msg: fmt.Sprintf("value expected but %s", arg)}
+	}
+
+	path := field.text
+	var typ reflect.Type
+	if ps.parser.Type != nil {
+		var err error
+		path, typ, err = ResolveField(ps.parser.Type, field.text)
+		if err != nil {
+			return &FilterError{Pos: field.pos, Msg: err.Error()}
+		}
+	}
+
+	if cmp.text == "=" || cmp.text == ":" {
+		if prefix, ok := filterPrefix(arg); ok {
+			if typ != nil && typ.Kind() != reflect.String {
+				return &FilterError{Pos: arg.pos, Msg: fmt.Sprintf("prefix match requires a string field but %s is %v", field.text, typ)}
+			}
+			ps.qb.Starts(field.text, prefix)
+			return nil
+		}
+	}
+	value, err := filterValue(arg, typ)
+	if err != nil {
+		return &FilterError{Pos: arg.pos, Msg: err.Error()}
+	}
+	switch cmp.text {
+	case "=", ":":
+		ps.qb.EqWithPath(field.text, path, value)
+	case "!=":
+		return &FilterError{Pos: cmp.pos, Msg: "!= is not supported"}
+	default:
+		ps.qb.Ineq(OperatorMap[cmp.text], field.text, value)
+	}
+	return nil
+}
+
+func filterPrefix(t *filterToken) (string, bool) {
+	if !strings.HasSuffix(t.text, "*") {
+		return "", false
+	}
+	prefix := strings.TrimSuffix(t.text, "*")
+	return prefix, !strings.Contains(prefix, "*")
+}
+
+func isFilterNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// filterValue converts the text of t to a value. Texts without quotes are
+// taken as numbers, booleans or null if possible.
+func filterValue(t *filterToken, typ reflect.Type) (interface{}, error) {
+	if strings.Contains(t.text, "*") {
+		return nil, fmt.Errorf("wildcard is supported only at the end of a string")
+	}
+	var v interface{} = t.text
+	if t.kind == filterText {
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			v = i
+		} else if f, err := strconv.ParseFloat(t.text, 64); err == nil {
+			v = f
+		} else {
+			switch t.text {
+			case "true":
+				v = true
+			case "false":
+				v = false
+			case "null":
+				v = nil
+			}
+		}
+	}
+	if typ == nil || v == nil {
+		return v, nil
+	}
+	if typ == timeType {
+		s, ok := v.(string)
+		if !ok {
+			s = t.text
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	if typ.Kind() == reflect.String {
+		v = t.text
+	}
+	return CoerceValue(v, typ)
+}
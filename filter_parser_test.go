@@ -0,0 +1,123 @@
+package querybuilder
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	{
+		b, err := ParseFilter(`Int1 >= 21 AND Str2:"ba*"`)
+		assert.NoError(t, err)
+		assert.Equal(t, New().Gte("Int1", int64(21)).Starts("Str2", "ba"), b)
+	}
+
+	{ // Sequence, parentheses and literals
+		b, err := ParseFilter(`(Str1 = 'it\'s' Int2 < -1.5) AND Flag = true AND Sub1.I1:3 AND Nil = null`)
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{
+			{"Str1", EQ, "it's"},
+			{"Int2", LT, -1.5},
+			{"Flag", EQ, true},
+			{"Sub1.I1", EQ, int64(3)},
+			{"Nil", EQ, nil},
+		}, b.Conditions)
+	}
+
+	{ // null
+		b, err := ParseFilter(`Name = null`)
+		assert.NoError(t, err)
+		q, assigns := b.Build(datastore.NewQuery(Kind4Test))
+		assert.Equal(t, datastore.NewQuery(Kind4Test).Filter("Name =", nil), q)
+		name := "foo"
+		r := &struct{ Name *string }{Name: &name}
+		assert.NoError(t, assigns.AssignAllMatched(&[]*struct{ Name *string }{r}))
+		assert.Nil(t, r.Name)
+	}
+
+	{ // Quoted numbers are strings
+		b, err := ParseFilter(`Str1 = "1"`)
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{{"Str1", EQ, "1"}}, b.Conditions)
+	}
+
+	{
+		b, err := ParseFilter("")
+		assert.NoError(t, err)
+		assert.Equal(t, New(), b)
+	}
+
+	type errorCase struct {
+		filter string
+		pos    int
+	}
+	for _, c := range []errorCase{
+		{`Int1 >= 21 OR Int2 = 1`, 11},
+		{`NOT Int1 = 1`, 0},
+		{`-Int1 = 1`, 0},
+		{`Int1 != 1`, 5},
+		{`Int1 = `, 7},
+		{`Int1 1`, 5},
+		{`Int1 = "abc`, 7},
+		{`(Int1 = 1`, 9},
+		{`Int1 = 1)`, 8},
+		{`()`, 1},
+		{`AND Int1 = 1`, 0},
+		{`Int1 = 1 AND`, 12},
+		{`Str1 = "a*b"`, 7},
+		{`1abc = 1`, 0},
+		{`Int1 ~ 1`, 5},
+	} {
+		_, err := ParseFilter(c.filter)
+		if assert.IsType(t, &FilterError{}, err, c.filter) {
+			assert.Equal(t, c.pos, err.(*FilterError).Pos, c.filter)
+		}
+	}
+
+	{ // Whitelist
+		_, err := ParseFilter(`Int1 = 1 AND Int2 = 2`, "Int1")
+		assert.Equal(t, &FilterError{Pos: 13, Msg: "field Int2 is not allowed"}, err)
+		assert.Equal(t, "Invalid filter at 13: field Int2 is not allowed", err.Error())
+	}
+}
+
+func TestFilterParserWithType(t *testing.T) {
+	type Color string
+	type Entity struct {
+		Name      string    `datastore:"name"`
+		Count     int       `datastore:"count"`
+		Score     float64   `datastore:"score"`
+		Color     Color     `datastore:"color"`
+		CreatedAt time.Time `datastore:"created_at"`
+	}
+	p := &FilterParser{Type: reflect.TypeOf(Entity{})}
+
+	{
+		b, err := p.Parse(`name = 123 AND count >= 2 AND score < 3 AND color = red AND created_at >= "2020-01-02T03:04:05Z"`)
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{
+			{"name", EQ, "123"},
+			{"count", GTE, 2},
+			{"score", LT, 3.0},
+			{"color", EQ, Color("red")},
+			{"created_at", GTE, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		}, b.Conditions)
+		assert.Equal(t, "Name", b.Assigns[0].Field)
+	}
+
+	for _, filter := range []string{
+		`unknown = 1`,
+		`count = 1.5`,
+		`count = "a"`,
+		`count:"1*"`,
+		`created_at > "yesterday"`,
+	} {
+		_, err := p.Parse(filter)
+		assert.IsType(t, &FilterError{}, err, filter)
+	}
+}
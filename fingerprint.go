@@ -0,0 +1,20 @@
+package querybuilder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+func (qb *QueryBuilder) CanonicalJSON() ([]byte, error) {
+	return json.Marshal(qb)
+}
+
+func (qb *QueryBuilder) Fingerprint() (string, error) {
+	b, err := qb.CanonicalJSON()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,84 @@
+package querybuilder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"cloud.google.com/go/datastore"
+)
+
+var (
+	geoPointType = reflect.TypeOf(datastore.GeoPoint{})
+	keyType      = reflect.TypeOf(&datastore.Key{})
+)
+
+// FromExample builds a QueryBuilder with Eq conditions for each non-zero
+// field of example. Nested structs are expanded into paths like Sub1.I1
+// and the fields of embedded structs are used as they are.
+// includeZero is a list of property names to add even if they are zero.
+// Multi-valued and noindex properties are not used.
+func FromExample(example interface{}, includeZero ...string) (*QueryBuilder, error) {
+	v := reflect.ValueOf(example)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Example type: %T is not a struct. %v", example, example)
+	}
+	qb := New()
+	qb.applyExample(v, "", "", Strings(includeZero))
+	return qb, nil
+}
+
+func (qb *QueryBuilder) applyExample(v reflect.Value, propPrefix, pathPrefix string, includeZero Strings) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" { // unexported
+			continue
+		}
+		name, tagged, options := sf.Name, false, Strings{}
+		if tag, ok := sf.Tag.Lookup("datastore"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name, tagged = parts[0], true
+			}
+			options = parts[1:]
+		}
+		if options.Has("noindex") {
+			continue
+		}
+		prop, path := propPrefix+name, pathPrefix+sf.Name
+		subProp, subPath := prop+".", path+"."
+		// Datastore saves the fields of embedded structs without names as
+		// the ones of the outer struct even if they are flattened. Paths keep
+		// the embedded field not to walk through nil pointers.
+		if sf.Anonymous && !tagged {
+			subProp = propPrefix
+		}
+
+		f := v.Field(i)
+		switch {
+		case f.Type() == timeType, f.Type() == geoPointType, f.Type() == keyType:
+		case f.Kind() == reflect.Struct:
+			qb.applyExample(f, subProp, subPath, includeZero)
+			continue
+		case f.Kind() == reflect.Ptr && f.Type().Elem().Kind() == reflect.Struct:
+			if !f.IsNil() {
+				qb.applyExample(f.Elem(), subProp, subPath, includeZero)
+			}
+			continue
+		case f.Kind() == reflect.Slice, f.Kind() == reflect.Array, f.Kind() == reflect.Map,
+			f.Kind() == reflect.Interface, f.Kind() == reflect.Ptr:
+			continue
+		}
+		if f.IsZero() && !includeZero.Has(prop) {
+			continue
+		}
+		qb.EqWithPath(prop, path, f.Interface())
+	}
+}
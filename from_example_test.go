@@ -0,0 +1,85 @@
+package querybuilder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromExample(t *testing.T) {
+	{
+		b, err := FromExample(&Entity4Test{Str1: "a", EnumA: EnumA2})
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{{"Str1", EQ, "a"}, {"EnumA", EQ, EnumA2}}, b.Conditions)
+		assert.Equal(t, Strings{"Str1", "EnumA"}, b.Ignored)
+		assert.Equal(t, Assigners{AssignerFor("Str1", "a"), AssignerFor("EnumA", EnumA2)}, b.Assigns)
+	}
+
+	{
+		b, err := FromExample(ComplicatedEntity4Test{Name: "Foo", Strings: []string{"a"}, Sub1: SubEntity{I1: 2}}, "ID")
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{{"ID", EQ, 0}, {"Name", EQ, "Foo"}, {"Sub1.I1", EQ, 2}}, b.Conditions)
+	}
+
+	{
+		type sub struct {
+			Code string `datastore:"code"`
+		}
+		type tagged struct {
+			Name      string    `datastore:"name"`
+			Memo      string    `datastore:",noindex"`
+			Secret    string    `datastore:"-"`
+			Sub       *sub      `datastore:"sub"`
+			CreatedAt time.Time `datastore:"created_at"`
+			internal  string
+		}
+		at := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+		example := &tagged{
+			Name: "foo", Memo: "memo", Secret: "secret", Sub: &sub{Code: "X"}, CreatedAt: at, internal: "x",
+		}
+		b, err := FromExample(example)
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{{"name", EQ, "foo"}, {"sub.code", EQ, "X"}, {"created_at", EQ, at}}, b.Conditions)
+		assert.Equal(t, Assigners{AssignerFor("Name", "foo"), AssignerFor("Sub.Code", "X"), AssignerFor("CreatedAt", at)}, b.Assigns)
+
+		b.Fields = Strings{"name", "sub.code", "created_at"}
+		assert.Equal(t, Strings{}, b.ProjectFields())
+
+		r := &tagged{Sub: &sub{}}
+		assert.NoError(t, b.Assigns.Assign(r))
+		assert.Equal(t, &tagged{Name: "foo", Sub: &sub{Code: "X"}, CreatedAt: at}, r)
+	}
+
+	{ // Embedded structs
+		type Base struct {
+			Code string `datastore:"code"`
+		}
+		type Audit struct {
+			By string
+		}
+		type Named struct {
+			Tag string
+		}
+		type embedding struct {
+			Base
+			*Audit `datastore:",flatten"`
+			Named  `datastore:"named"`
+			Name   string
+		}
+		b, err := FromExample(&embedding{Base: Base{Code: "X"}, Audit: &Audit{By: "foo"}, Named: Named{Tag: "t"}, Name: "bar"})
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{{"code", EQ, "X"}, {"By", EQ, "foo"}, {"named.Tag", EQ, "t"}, {"Name", EQ, "bar"}}, b.Conditions)
+
+		assert.Equal(t, Assigners{AssignerFor("Base.Code", "X"), AssignerFor("Audit.By", "foo"), AssignerFor("Named.Tag", "t"), AssignerFor("Name", "bar")}, b.Assigns)
+
+		r := &embedding{Audit: &Audit{}}
+		assert.NoError(t, b.Assigns.Assign(r))
+		assert.Equal(t, &embedding{Base: Base{Code: "X"}, Audit: &Audit{By: "foo"}, Named: Named{Tag: "t"}, Name: "bar"}, r)
+	}
+
+	{
+		_, err := FromExample("foo")
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,139 @@
+package querybuilder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const StructTagName = "qb"
+
+// FromStruct builds a QueryBuilder from a struct whose fields have qb tags
+// like `qb:"Age,gte"`. The first part is the field of the entity. The
+// struct field name is used when it's empty. The second part is one of
+// eq, lt, lte, gt, gte, starts, sort, offset, limit or an Ope.
+// nil and zero values are skipped unless the tag has zero option.
+func FromStruct(params interface{}) (*QueryBuilder, error) {
+	qb := New()
+	if err := qb.ApplyStruct(params); err != nil {
+		return nil, err
+	}
+	return qb, nil
+}
+
+func (qb *QueryBuilder) ApplyStruct(params interface{}) error {
+	v := reflect.ValueOf(params)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("Params type: %T is not a struct. %v", params, params)
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" { // unexported
+			continue
+		}
+		f := v.Field(i)
+		tag, ok := sf.Tag.Lookup(StructTagName)
+		if !ok {
+			if sf.Anonymous && reflect.Indirect(f).Kind() == reflect.Struct {
+				if f.Kind() == reflect.Ptr && f.IsNil() {
+					continue
+				}
+				if err := qb.ApplyStruct(f.Interface()); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		if len(parts) < 2 {
+			return fmt.Errorf("No operator in %s tag of %s: %q", StructTagName, sf.Name, tag)
+		}
+		field, op, options := parts[0], parts[1], Strings(parts[2:])
+		if field == "" {
+			field = sf.Name
+		}
+		// Tags are validated even if the value is skipped
+		if err := checkTaggedType(op, sf.Type); err != nil {
+			return fmt.Errorf("%s: %v", sf.Name, err)
+		}
+		if f.Kind() == reflect.Ptr {
+			if f.IsNil() {
+				continue
+			}
+			f = f.Elem()
+		} else if f.IsZero() && !options.Has("zero") {
+			continue
+		}
+		qb.applyTaggedValue(field, op, f)
+	}
+	return nil
+}
+
+// checkTaggedType validates op and the type of the values for it.
+func checkTaggedType(op string, t reflect.Type) error {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch op {
+	case "eq", "lt", "lte", "gt", "gte":
+	case "starts":
+		if t.Kind() != reflect.String {
+			return fmt.Errorf("starts requires a string but was %v", t)
+		}
+	case "sort":
+		if t.Kind() != reflect.String && !(t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String) {
+			return fmt.Errorf("sort requires strings but was %v", t)
+		}
+	case "offset", "limit":
+		if !isIntKind(t.Kind()) {
+			return fmt.Errorf("%s requires an integer but was %v", op, t)
+		}
+	default:
+		if _, ok := OperatorMap[op]; !ok {
+			return fmt.Errorf("Unknown operator: %q", op)
+		}
+	}
+	return nil
+}
+
+// applyTaggedValue applies f for op validated by checkTaggedType.
+func (qb *QueryBuilder) applyTaggedValue(field, op string, f reflect.Value) {
+	switch op {
+	case "eq":
+		qb.Eq(field, f.Interface())
+	case "lt":
+		qb.Lt(field, f.Interface())
+	case "lte":
+		qb.Lte(field, f.Interface())
+	case "gt":
+		qb.Gt(field, f.Interface())
+	case "gte":
+		qb.Gte(field, f.Interface())
+	case "starts":
+		qb.Starts(field, f.String())
+	case "sort":
+		if f.Kind() == reflect.String {
+			qb.AddSort(f.String())
+		} else {
+			for i := 0; i < f.Len(); i++ {
+				qb.AddSort(f.Index(i).String())
+			}
+		}
+	case "offset", "limit":
+		qb.AddIntFilter(op, int(toInt64(f)))
+	default:
+		ope := OperatorMap[op]
+		if ope == EQ {
+			qb.Eq(field, f.Interface())
+		} else {
+			qb.Ineq(ope, field, f.Interface())
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package querybuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromStruct(t *testing.T) {
+	type Paging struct {
+		Offset int `qb:",offset"`
+		Limit  int `qb:",limit"`
+	}
+	type ListParams struct {
+		MinInt1 *int     `qb:"Int1,gte"`
+		MaxInt1 *int     `qb:"Int1,<"`
+		Str2    string   `qb:",starts"`
+		EnumA   EnumA    `qb:",eq"`
+		Int2    int      `qb:",eq,zero"`
+		Sort    []string `qb:",sort"`
+		Memo    string   `qb:"-"`
+		Other   string
+		Paging
+	}
+
+	{
+		min := 0
+		b, err := FromStruct(&ListParams{
+			MinInt1: &min,
+			Str2:    "ba",
+			EnumA:   EnumA2,
+			Sort:    []string{"-Str1"},
+			Memo:    "ignored",
+			Other:   "ignored",
+			Paging:  Paging{Limit: 10},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{
+			{"Int1", GTE, 0},
+			{"Str2", GTE, "ba"},
+			{"Str2", LT, "bb"},
+			{"EnumA", EQ, EnumA2},
+			{"Int2", EQ, 0},
+		}, b.Conditions)
+		assert.Equal(t, Strings{"Str2", "Int1", "-Str1"}, b.SortFields)
+		assert.Equal(t, Strings{"EnumA", "Int2"}, b.Ignored)
+		assert.Equal(t, []*ValuedFilter{{Name: "limit", IntValue: 10}}, b.Filters)
+	}
+
+	{
+		max := 5
+		b, err := FromStruct(ListParams{MaxInt1: &max})
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{{"Int1", LT, 5}, {"Int2", EQ, 0}}, b.Conditions)
+	}
+
+	{
+		type invalid struct {
+			Name string `qb:"Name,like"`
+		}
+		_, err := FromStruct(&invalid{Name: "foo"})
+		assert.Error(t, err)
+		// Invalid tags are rejected even for zero values
+		_, err = FromStruct(&invalid{})
+		assert.Error(t, err)
+	}
+	{
+		type invalid struct {
+			Name *int `qb:"Name,starts"`
+		}
+		_, err := FromStruct(&invalid{})
+		assert.Error(t, err)
+	}
+	{ // Unexported fields are skipped
+		type params struct {
+			Name    string `qb:"Name,eq"`
+			private string `qb:"Private,eq"`
+		}
+		b, err := FromStruct(&params{Name: "foo", private: "bar"})
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{{"Name", EQ, "foo"}}, b.Conditions)
+	}
+	{
+		type invalid struct {
+			Name string `qb:"Name"`
+		}
+		_, err := FromStruct(&invalid{Name: "foo"})
+		assert.Error(t, err)
+	}
+	{
+		type invalid struct {
+			Name int `qb:"Name,starts"`
+		}
+		_, err := FromStruct(&invalid{Name: 1})
+		assert.Error(t, err)
+	}
+	{
+		_, err := FromStruct(1)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,196 @@
+package querybuilder
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"cloud.google.com/go/datastore"
+)
+
+var GeohashFieldSuffix = "Geohash"
+
+func GeohashField(field string) string {
+	return field + GeohashFieldSuffix
+}
+
+type GeoFilter struct {
+	Field          string  `json:"field"`
+	Lat            float64 `json:"lat"`
+	Lng            float64 `json:"lng"`
+	Radius         float64 `json:"radius"`
+	SortByDistance bool    `json:"sort_by_distance,omitempty"`
+}
+
+func (g *GeoFilter) Distance(entity interface{}) (float64, error) {
+	e := reflect.ValueOf(entity)
+	if e.Type().Kind() == reflect.Ptr {
+		e = e.Elem()
+	}
+	var r float64
+	err := ReflectWalkIn(&e, g.Field, ".", func(f *reflect.Value) error {
+		p, ok := f.Interface().(datastore.GeoPoint)
+		if !ok {
+			return fmt.Errorf("%s is not a datastore.GeoPoint but %v", g.Field, f.Type())
+		}
+		r = Distance(g.Lat, g.Lng, p.Lat, p.Lng)
+		return nil
+	})
+	return r, err
+}
+
+// Near finds entities within radius meters from point. It requires
+// the companion property named by GeohashField which AssignGeohash sets.
+func (qb *QueryBuilder) Near(field string, point datastore.GeoPoint, radius float64) *QueryBuilder {
+	qb.Geo = &GeoFilter{Field: field, Lat: point.Lat, Lng: point.Lng, Radius: radius}
+	return qb
+}
+
+func (qb *QueryBuilder) OrderByDistance() *QueryBuilder {
+	if qb.Geo != nil {
+		qb.Geo.SortByDistance = true
+	}
+	return qb
+}
+
+// GeoQueryBuilders returns builders for each geohash prefix range
+// which covers the circle of qb.Geo.
+func (qb *QueryBuilder) GeoQueryBuilders() []*QueryBuilder {
+	r := []*QueryBuilder{}
+	if qb.Geo == nil {
+		return r
+	}
+	for _, prefix := range GeohashesCovering(qb.Geo.Lat, qb.Geo.Lng, qb.Geo.Radius) {
+		b := qb.WithoutFilters()
+		b.Geo = nil
+		b.Cursor = ""
+		if len(b.Fields) > 0 && !b.Fields.Has(qb.Geo.Field) {
+			b.Fields = append(b.Fields, qb.Geo.Field)
+		}
+		r = append(r, b.Starts(GeohashField(qb.Geo.Field), prefix))
+	}
+	return r
+}
+
+func AssignGeohash(entity interface{}, field string) error {
+	e := reflect.ValueOf(entity)
+	if e.Type().Kind() == reflect.Ptr {
+		e = e.Elem()
+	}
+	hashes := []string{}
+	err := ReflectWalkIn(&e, field, ".", func(f *reflect.Value) error {
+		p, ok := f.Interface().(datastore.GeoPoint)
+		if !ok {
+			return fmt.Errorf("%s is not a datastore.GeoPoint but %v", field, f.Type())
+		}
+		hashes = append(hashes, EncodeGeohash(p.Lat, p.Lng, GeohashPrecision))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ReflectWalkIn(&e, GeohashField(field), ".", func(f *reflect.Value) error {
+		if f.Kind() != reflect.String {
+			return fmt.Errorf("%s is not a string but %v", GeohashField(field), f.Type())
+		}
+		f.SetString(hashes[0])
+		hashes = hashes[1:]
+		return nil
+	})
+}
+
+type geoResult struct {
+	key      *datastore.Key
+	entity   reflect.Value
+	distance float64
+	row      Row
+}
+
+func (e *Executor) getAllNear(ctx context.Context, qb *QueryBuilder, dst interface{}) ([]*datastore.Key, error) {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Unsupported type of destination %T", dst)
+	}
+	dv = dv.Elem()
+	if qb.Cursor != "" {
+		return nil, fmt.Errorf("Can't use a cursor with geo filter on %s", qb.Geo.Field)
+	}
+
+	results := []*geoResult{}
+	seen := map[string]bool{}
+	for _, b := range qb.GeoQueryBuilders() {
+		page := reflect.New(dv.Type())
+		keys, err := e.getAll(ctx, b, page.Interface())
+		if err != nil {
+			return nil, err
+		}
+		for i, key := range keys {
+			if seen[key.String()] {
+				continue
+			}
+			seen[key.String()] = true
+			entity := page.Elem().Index(i)
+			d, err := qb.Geo.Distance(entity.Interface())
+			if err != nil {
+				return nil, err
+			}
+			if d <= qb.Geo.Radius {
+				results = append(results, &geoResult{key: key, entity: entity, distance: d})
+			}
+		}
+	}
+	if qb.Geo.SortByDistance {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].distance < results[j].distance
+		})
+	} else if err := sortGeoResults(qb, results); err != nil {
+		return nil, err
+	}
+
+	if offset, ok := qb.IntFilterValue("offset"); ok {
+		if offset < 0 {
+			return nil, fmt.Errorf("Invalid offset: %d", offset)
+		}
+		if offset > len(results) {
+			offset = len(results)
+		}
+		results = results[offset:]
+	}
+	// Datastore takes a negative limit as no limit
+	if limit, ok := qb.IntFilterValue("limit"); ok && limit >= 0 && limit < len(results) {
+		results = results[:limit]
+	}
+
+	keys := []*datastore.Key{}
+	for _, r := range results {
+		dv.Set(reflect.Append(dv, r.entity))
+		keys = append(keys, r.key)
+	}
+	return keys, nil
+}
+
+// sortGeoResults sorts the results merged from the cells in the order of
+// the sort fields, or the keys without them as Datastore does.
+func sortGeoResults(qb *QueryBuilder, results []*geoResult) error {
+	fields := qb.ListSortFields()
+	if len(fields) == 0 {
+		fields = Strings{KeyField}
+	}
+	for _, r := range results {
+		row, err := qb.RowOf(r.entity.Interface(), r.key)
+		if err != nil {
+			return err
+		}
+		r.row = row
+	}
+	var err error
+	sort.SliceStable(results, func(i, j int) bool {
+		c, cerr := compareRows(fields, results[i].row, results[j].row)
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+		return c < 0
+	})
+	return err
+}
@@ -0,0 +1,119 @@
+package querybuilder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeohash(t *testing.T) {
+	assert.Equal(t, "ezs42", EncodeGeohash(42.605, -5.603, 5))
+	assert.Equal(t, "xn76urx6", EncodeGeohash(35.681236, 139.767125, 8)) // Tokyo station
+
+	{
+		h, w := GeohashCellSize(5)
+		assert.InDelta(t, 0.0439453125, h, 1e-12)
+		assert.InDelta(t, 0.0439453125, w, 1e-12)
+	}
+
+	// Tokyo station to Shin-Osaka station
+	assert.InDelta(t, 401700, Distance(35.681236, 139.767125, 34.733165, 135.500214), 100)
+
+	{
+		prefixes := GeohashesCovering(35.681236, 139.767125, 1000)
+		assert.Equal(t, 9, len(prefixes))
+		assert.True(t, Strings(prefixes).Has("xn76u"))
+	}
+	{
+		prefixes := GeohashesCovering(89.99, 0, 1000)
+		assert.Equal(t, 6, len(prefixes))
+	}
+}
+
+type Place4Test struct {
+	Name            string
+	Location        datastore.GeoPoint
+	LocationGeohash string
+}
+
+func TestNear(t *testing.T) {
+	places := []*Place4Test{
+		{Name: "Tokyo", Location: datastore.GeoPoint{Lat: 35.681236, Lng: 139.767125}},
+		{Name: "Yurakucho", Location: datastore.GeoPoint{Lat: 35.675069, Lng: 139.763328}},
+		{Name: "Kanda", Location: datastore.GeoPoint{Lat: 35.691690, Lng: 139.770883}},
+		{Name: "Shinjuku", Location: datastore.GeoPoint{Lat: 35.690921, Lng: 139.700258}},
+	}
+	for _, p := range places {
+		assert.NoError(t, AssignGeohash(p, "Location"))
+	}
+	assert.Equal(t, "xn76urx6", places[0].LocationGeohash[:8])
+
+	center := datastore.GeoPoint{Lat: 35.681236, Lng: 139.767125}
+
+	{
+		b := New().Near("Location", center, 1500)
+		subs := b.GeoQueryBuilders()
+		assert.Equal(t, 9, len(subs))
+		for _, sub := range subs {
+			assert.Nil(t, sub.Geo)
+			assert.Equal(t, Strings{"LocationGeohash"}, sub.SortFields)
+			assert.Equal(t, 2, len(sub.Conditions))
+		}
+		assert.Nil(t, b.Conditions)
+	}
+
+	{
+		b := New().Near("Location", center, 1500).OrderByDistance().Limit(2)
+		bytes, err := json.Marshal(b)
+		assert.NoError(t, err)
+		assert.Contains(t, string(bytes), `"geo":{"field":"Location","lat":35.681236,"lng":139.767125,"radius":1500,"sort_by_distance":true}`)
+
+		cli := &fakeClient{entities: places}
+		var results []*Place4Test
+		keys, err := NewExecutor(cli, "Place").GetAll(context.Background(), b, &results)
+		assert.NoError(t, err)
+		assert.Equal(t, 9, cli.getAllCalls)
+		assert.Equal(t, 2, len(keys))
+		names := []string{}
+		for _, r := range results {
+			names = append(names, r.Name)
+		}
+		assert.Equal(t, []string{"Tokyo", "Yurakucho"}, names)
+	}
+
+	{ // Sorted by the sort fields
+		b := New().Near("Location", center, 1500).Desc("Name").Offset(1).Limit(-1)
+		var results []*Place4Test
+		_, err := NewExecutor(&fakeClient{entities: places}, "Place").GetAll(context.Background(), b, &results)
+		assert.NoError(t, err)
+		names := []string{}
+		for _, r := range results {
+			names = append(names, r.Name)
+		}
+		assert.Equal(t, []string{"Tokyo", "Kanda"}, names)
+	}
+
+	{
+		_, err := NewExecutor(&fakeClient{}, "Place").Count(context.Background(), New().Near("Location", center, 1500))
+		assert.Error(t, err)
+	}
+
+	{ // Invalid paging
+		e := NewExecutor(&fakeClient{entities: places}, "Place")
+		var results []*Place4Test
+		_, err := e.GetAll(context.Background(), New().Near("Location", center, 1500).Offset(-1), &results)
+		assert.Error(t, err)
+		cursor, err := datastore.DecodeCursor("Y3Vyc29y")
+		assert.NoError(t, err)
+		b := New().Near("Location", center, 1500).StartAt(cursor)
+		for _, sub := range b.GeoQueryBuilders() {
+			assert.Equal(t, "", sub.Cursor)
+		}
+		_, err = e.getAllNear(context.Background(), b, &results)
+		assert.Error(t, err)
+	}
+}
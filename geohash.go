@@ -0,0 +1,98 @@
+package querybuilder
+
+import (
+	"math"
+	"strings"
+)
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+const (
+	EarthRadius      = 6371008.8 // meters
+	GeohashPrecision = 10
+)
+
+func EncodeGeohash(lat, lng float64, precision int) string {
+	latMin, latMax := -90.0, 90.0
+	lngMin, lngMax := -180.0, 180.0
+	var b strings.Builder
+	bit, ch, even := 0, 0, true
+	for b.Len() < precision {
+		if even {
+			mid := (lngMin + lngMax) / 2
+			if lng >= mid {
+				ch = ch<<1 | 1
+				lngMin = mid
+			} else {
+				ch = ch << 1
+				lngMax = mid
+			}
+		} else {
+			mid := (latMin + latMax) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				latMin = mid
+			} else {
+				ch = ch << 1
+				latMax = mid
+			}
+		}
+		even = !even
+		bit++
+		if bit == 5 {
+			b.WriteByte(geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return b.String()
+}
+
+// GeohashCellSize returns the height and width in degrees of cells
+// of the given precision.
+func GeohashCellSize(precision int) (float64, float64) {
+	bits := 5 * precision
+	latBits := bits / 2
+	lngBits := bits - latBits
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(lngBits))
+}
+
+// GeohashesCovering returns the prefixes of the cells which cover the circle.
+func GeohashesCovering(lat, lng, radius float64) []string {
+	precision := 1
+	for p := GeohashPrecision; p > 0; p-- {
+		h, w := GeohashCellSize(p)
+		hm := h * math.Pi / 180 * EarthRadius
+		wm := w * math.Pi / 180 * EarthRadius * math.Cos(lat*math.Pi/180)
+		if hm >= radius && wm >= radius {
+			precision = p
+			break
+		}
+	}
+	h, w := GeohashCellSize(precision)
+	r := Strings{}
+	for _, dlat := range []float64{-h, 0, h} {
+		la := lat + dlat
+		if la < -90 || la > 90 {
+			continue
+		}
+		for _, dlng := range []float64{-w, 0, w} {
+			ln := lng + dlng
+			if ln < -180 {
+				ln += 360
+			} else if ln >= 180 {
+				ln -= 360
+			}
+			r = append(r, EncodeGeohash(la, ln, precision))
+		}
+	}
+	return r.Uniq()
+}
+
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := math.Pi / 180
+	dlat := (lat2 - lat1) * rad
+	dlng := (lng2 - lng1) * rad
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dlng/2)*math.Sin(dlng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
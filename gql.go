@@ -0,0 +1,137 @@
+package querybuilder
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+var gqlNamePattern = regexp.MustCompile(`\A[A-Za-z_$][A-Za-z0-9_$]*\z`)
+
+// GQL returns the GQL of the query which Build makes for kind.
+// Conditions evaluated on client and Geo filter are not included.
+func (qb *QueryBuilder) GQL(kind string) string {
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	if fields := qb.ProjectFields(); len(fields) > 0 {
+		for i, f := range fields {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(GQLName(f))
+		}
+	} else {
+		b.WriteString("*")
+	}
+	b.WriteString(" FROM ")
+	b.WriteString(GQLName(kind))
+	b.WriteString(qb.gqlWhere())
+	if sorts := qb.ListSortFields(); len(sorts) > 0 {
+		b.WriteString(" ORDER BY ")
+		for i, f := range sorts {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if strings.HasPrefix(f, "-") {
+				b.WriteString(GQLName(f[1:]) + " DESC")
+			} else {
+				b.WriteString(GQLName(f) + " ASC")
+			}
+		}
+	}
+	if limit, ok := qb.IntFilterValue("limit"); ok {
+		b.WriteString(" LIMIT " + strconv.Itoa(limit))
+	}
+	if offset, ok := qb.IntFilterValue("offset"); ok {
+		b.WriteString(" OFFSET " + strconv.Itoa(offset))
+	}
+	return b.String()
+}
+
+// CountGQL returns the GQL of the aggregation query counting the entities
+// which BuildForCount matches.
+func (qb *QueryBuilder) CountGQL(kind string) string {
+	return "AGGREGATE COUNT(*) OVER (SELECT * FROM " + GQLName(kind) + qb.gqlWhere() + ")"
+}
+
+func (qb *QueryBuilder) gqlWhere() string {
+	conds := qb.ServerConditions()
+	if len(conds) == 0 {
+		return ""
+	}
+	parts := make([]string, len(conds))
+	for i, c := range conds {
+		parts[i] = GQLName(c.Field) + " " + c.Ope.String() + " " + GQLValue(c.Value)
+	}
+	return " WHERE " + strings.Join(parts, " AND ")
+}
+
+// GQLName quotes name with backquotes unless it's a simple identifier.
+func GQLName(name string) string {
+	if gqlNamePattern.MatchString(name) {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func GQLValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case Param:
+		return "@" + x.Name
+	case string:
+		return gqlString(x)
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(x))
+	case time.Time:
+		return "DATETIME(" + gqlString(x.UTC().Format(time.RFC3339Nano)) + ")"
+	case *datastore.Key:
+		return gqlKey(x)
+	case datastore.GeoPoint:
+		return fmt.Sprintf("GEOPT(%v, %v)", x.Lat, x.Lng)
+	case []byte:
+		return "BLOB(" + gqlString(base64.StdEncoding.EncodeToString(x)) + ")"
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case isIntKind(rv.Kind()):
+		return strconv.FormatInt(toInt64(rv), 10)
+	case isNumberKind(rv.Kind()):
+		s := strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eN") {
+			s += ".0" // Not to be an integer
+		}
+		return s
+	case rv.Kind() == reflect.String:
+		return gqlString(rv.String())
+	default:
+		return gqlString(fmt.Sprintf("%v", v))
+	}
+}
+
+func gqlString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`)
+	return "'" + r.Replace(s) + "'"
+}
+
+func gqlKey(k *datastore.Key) string {
+	if k == nil {
+		return "NULL"
+	}
+	parts := []string{}
+	for ; k != nil; k = k.Parent {
+		id := strconv.FormatInt(k.ID, 10)
+		if k.Name != "" {
+			id = gqlString(k.Name)
+		}
+		parts = append([]string{GQLName(k.Kind) + ", " + id}, parts...)
+	}
+	return "KEY(" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,41 @@
+package querybuilder
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGQL(t *testing.T) {
+	{
+		assert.Equal(t, "SELECT * FROM entity4test", New().GQL(Kind4Test))
+		assert.Equal(t, "AGGREGATE COUNT(*) OVER (SELECT * FROM entity4test)", New().CountGQL(Kind4Test))
+	}
+
+	{
+		b := New("Int2", "Str1", "Str2").Eq("Int2", 1)
+		assert.Equal(t, "SELECT Str1, Str2 FROM entity4test WHERE Int2 = 1", b.GQL(Kind4Test))
+	}
+
+	{
+		b := New().Eq("Str1", "it's").Gte("Int1", 2).Desc("Str2").Offset(10).Limit(5)
+		assert.Equal(t, "SELECT * FROM entity4test WHERE Str1 = 'it\\'s' AND Int1 >= 2 ORDER BY Int1 ASC, Str2 DESC LIMIT 5 OFFSET 10", b.GQL(Kind4Test))
+		assert.Equal(t, "AGGREGATE COUNT(*) OVER (SELECT * FROM entity4test WHERE Str1 = 'it\\'s' AND Int1 >= 2)", b.CountGQL(Kind4Test))
+	}
+
+	{ // Client side conditions are not included
+		b := New().Gte("Int1", 2).Lt("Int2", 5).EvaluateIneqOnClient(0)
+		assert.Equal(t, "SELECT * FROM entity4test WHERE Int2 < 5 ORDER BY Int2 ASC, Int1 ASC", b.GQL(Kind4Test))
+	}
+
+	{
+		b := New().Eq("Sub1.I1", 1.0).Eq("Flag", true).Eq("Param", Placeholder("p")).
+			Eq("At", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)).
+			Eq("Parent", datastore.NameKey("Parent", "a", nil)).Eq("Nil", nil)
+		assert.Equal(t, "SELECT * FROM `my-kind` WHERE `Sub1.I1` = 1.0 AND Flag = TRUE AND Param = @p"+
+			" AND At = DATETIME('2020-01-02T03:04:05Z') AND Parent = KEY(Parent, 'a') AND Nil = NULL", b.GQL("my-kind"))
+	}
+}
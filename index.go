@@ -0,0 +1,95 @@
+package querybuilder
+
+import (
+	"strings"
+)
+
+// Index is a composite index of Datastore in the form of index.yaml.
+type Index struct {
+	Kind       string
+	Properties []*IndexProperty
+}
+
+type IndexProperty struct {
+	Name string
+	Desc bool
+}
+
+// RequiredIndex returns the composite index which the query built for kind
+// needs, or nil if the built-in indexes are enough. Properties with
+// equality filters come first, then the sort orders and the projected
+// properties. The key in ascending order is omitted because every index
+// has it implicitly.
+func (qb *QueryBuilder) RequiredIndex(kind string) *Index {
+	props := []*IndexProperty{}
+	names := Strings{}
+	add := func(name string, desc bool) {
+		if !names.Has(name) {
+			names = append(names, name)
+			props = append(props, &IndexProperty{Name: name, Desc: desc})
+		}
+	}
+
+	conds := qb.ServerConditions()
+	eqs := conds.Select(func(c *Condition) bool { return c.Ope == EQ && c.Field != KeyField })
+	for _, c := range eqs {
+		add(c.Field, false)
+	}
+	sorts := Strings{}
+	for _, f := range conds.IneqFields() {
+		if f != KeyField && !qb.ListSortFields().Has(f) && !qb.ListSortFields().Has("-"+f) {
+			sorts = append(sorts, f)
+		}
+	}
+	sorts = append(sorts, qb.ListSortFields()...)
+	ordered := 0
+	for _, s := range sorts {
+		name := strings.TrimPrefix(s, "-")
+		desc := strings.HasPrefix(s, "-")
+		if name == KeyField && !desc {
+			continue
+		}
+		add(name, desc)
+		ordered++
+	}
+	projected := qb.ProjectFields()
+	for _, f := range projected {
+		add(f, false)
+	}
+
+	switch {
+	case len(props) <= 1:
+		// Built-in index of the property or the key
+		return nil
+	case ordered == 0 && len(projected) == 0:
+		// Merge join of the built-in indexes for equality filters
+		return nil
+	}
+	return &Index{Kind: kind, Properties: props}
+}
+
+// YAML returns the index in the format of index.yaml.
+func (idx *Index) YAML() string {
+	var b strings.Builder
+	b.WriteString("- kind: " + idx.Kind + "\n")
+	b.WriteString("  properties:\n")
+	for _, p := range idx.Properties {
+		b.WriteString("  - name: " + p.Name + "\n")
+		if p.Desc {
+			b.WriteString("    direction: desc\n")
+		}
+	}
+	return b.String()
+}
+
+// IndexYAML returns index.yaml for indexes.
+func IndexYAML(indexes ...*Index) string {
+	var b strings.Builder
+	b.WriteString("indexes:\n")
+	for _, idx := range indexes {
+		if idx != nil {
+			b.WriteString(idx.YAML())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package querybuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredIndex(t *testing.T) {
+	// Built-in indexes
+	assert.Nil(t, New().RequiredIndex(Kind4Test))
+	assert.Nil(t, New().Eq("Int1", 1).Eq("Str1", "a").RequiredIndex(Kind4Test))
+	assert.Nil(t, New().Gte("Int1", 1).Desc("Int1").RequiredIndex(Kind4Test))
+	assert.Nil(t, New().Desc(KeyField).RequiredIndex(Kind4Test))
+	assert.Nil(t, New().Asc("Int1").TieBreakByKey().RequiredIndex(Kind4Test))
+
+	{
+		idx := New().Eq("Str1", "a").Gte("Int1", 1).Desc("Int2").RequiredIndex(Kind4Test)
+		assert.Equal(t, &Index{Kind: Kind4Test, Properties: []*IndexProperty{
+			{Name: "Str1"}, {Name: "Int1"}, {Name: "Int2", Desc: true},
+		}}, idx)
+		assert.Equal(t, "indexes:\n"+
+			"- kind: entity4test\n"+
+			"  properties:\n"+
+			"  - name: Str1\n"+
+			"  - name: Int1\n"+
+			"  - name: Int2\n"+
+			"    direction: desc\n", IndexYAML(idx))
+	}
+
+	{ // Key tie-breaker in descending order needs to be indexed
+		b := New().Desc("Int2").TieBreakByKey()
+		assert.Equal(t, &Index{Kind: Kind4Test, Properties: []*IndexProperty{
+			{Name: "Int2", Desc: true}, {Name: KeyField, Desc: true},
+		}}, b.RequiredIndex(Kind4Test))
+	}
+
+	{ // Projection
+		b := New("Int1", "Str1", "Str2").Eq("Str1", "a").Asc("Int2").TieBreakByKey()
+		assert.Equal(t, &Index{Kind: Kind4Test, Properties: []*IndexProperty{
+			{Name: "Str1"}, {Name: "Int2"}, {Name: "Int1"}, {Name: "Str2"},
+		}}, b.RequiredIndex(Kind4Test))
+	}
+}
+
+func TestKeyTieBreaker(t *testing.T) {
+	{
+		b := New("Int1", "Int2").Asc("Int2").Offset(2).Limit(2).TieBreakByKey()
+		assert.Equal(t, Strings{"Int2"}, b.SortFields)
+		assert.Equal(t, Strings{"Int2", KeyField}, b.ListSortFields())
+		assert.Equal(t, "SELECT Int1, Int2 FROM entity4test ORDER BY Int2 ASC, __key__ ASC LIMIT 2 OFFSET 2", b.GQL(Kind4Test))
+		// The key is not projected
+		assert.Equal(t, Strings{"Int1", "Int2"}, b.ProjectFields())
+	}
+	{
+		b := New().Gte("Int1", 2).Desc("Str1").TieBreakByKey()
+		assert.Equal(t, Strings{"Int1", "-Str1", "-" + KeyField}, b.ListSortFields())
+	}
+	{ // Already sorted by key
+		b := New().Asc("Int2").Desc(KeyField).TieBreakByKey()
+		assert.Equal(t, Strings{"Int2", "-" + KeyField}, b.ListSortFields())
+	}
+	{ // Sorted by key by default
+		assert.Equal(t, Strings(nil), New().TieBreakByKey().ListSortFields())
+	}
+	{ // Page tokens are bound to the tie-breaker
+		a, err := New().Asc("Int2").PageFingerprint()
+		assert.NoError(t, err)
+		b, err := New().Asc("Int2").TieBreakByKey().PageFingerprint()
+		assert.NoError(t, err)
+		assert.NotEqual(t, a, b)
+	}
+}
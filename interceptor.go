@@ -0,0 +1,47 @@
+package querybuilder
+
+import (
+	"cloud.google.com/go/datastore"
+)
+
+type BuildMode int
+
+const (
+	CountMode BuildMode = iota
+	ListMode
+)
+
+func (m BuildMode) String() string {
+	switch m {
+	case CountMode:
+		return "count"
+	case ListMode:
+		return "list"
+	default:
+		return "unknown"
+	}
+}
+
+// BuildFunc builds q from qb. It's called with CountMode by BuildForCount
+// and with ListMode by BuildForList, so Build calls it twice.
+type BuildFunc func(mode BuildMode, qb *QueryBuilder, q *datastore.Query) (*datastore.Query, Assigners)
+
+type Interceptor func(next BuildFunc) BuildFunc
+
+// Use adds interceptors which are called in the order they're added.
+// They get a copy of the builder so that they can modify it.
+func (qb *QueryBuilder) Use(interceptors ...Interceptor) *QueryBuilder {
+	qb.interceptors = append(qb.interceptors, interceptors...)
+	return qb
+}
+
+func (qb *QueryBuilder) intercept(mode BuildMode, q *datastore.Query, base BuildFunc) (*datastore.Query, Assigners) {
+	if len(qb.interceptors) == 0 {
+		return base(mode, qb, q)
+	}
+	f := base
+	for i := len(qb.interceptors) - 1; i >= 0; i-- {
+		f = qb.interceptors[i](f)
+	}
+	return f(mode, qb.Clone(), q)
+}
@@ -0,0 +1,92 @@
+package querybuilder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterceptors(t *testing.T) {
+	calls := []string{}
+	record := func(name string) Interceptor {
+		return func(next BuildFunc) BuildFunc {
+			return func(mode BuildMode, qb *QueryBuilder, q *datastore.Query) (*datastore.Query, Assigners) {
+				calls = append(calls, name+":"+mode.String())
+				return next(mode, qb, q)
+			}
+		}
+	}
+
+	var built []*QueryBuilder
+	capture := func(next BuildFunc) BuildFunc {
+		return func(mode BuildMode, qb *QueryBuilder, q *datastore.Query) (*datastore.Query, Assigners) {
+			built = append(built, qb)
+			return next(mode, qb, q)
+		}
+	}
+
+	limitFlag := true
+	rewrite := func(next BuildFunc) BuildFunc {
+		return func(mode BuildMode, qb *QueryBuilder, q *datastore.Query) (*datastore.Query, Assigners) {
+			if limitFlag && mode == ListMode {
+				qb.Limit(50)
+			}
+			return next(mode, qb, q)
+		}
+	}
+
+	b := New("Int1", "Str1").Eq("Str1", "a").Use(record("outer"), rewrite, record("inner"), capture)
+	q, assigns := b.Build(datastore.NewQuery(Kind4Test))
+	assert.NotNil(t, q)
+	assert.Equal(t, Assigners{AssignerFor("Str1", "a")}, assigns)
+	assert.Equal(t, []string{"outer:count", "inner:count", "outer:list", "inner:list"}, calls)
+	if assert.Equal(t, 2, len(built)) {
+		assert.Equal(t, 0, len(built[0].Filters))
+		assert.Equal(t, []*ValuedFilter{{Name: "limit", IntValue: 50}}, built[1].Filters)
+	}
+	// The builder itself is not changed by interceptors
+	assert.Equal(t, 0, len(b.Filters))
+
+	{ // Executor
+		calls = []string{}
+		e := NewExecutor(&fakeClient{entities: Entities}, Kind4Test)
+		e.Interceptors = []Interceptor{record("executor")}
+		c, err := e.Count(context.Background(), New())
+		assert.NoError(t, err)
+		assert.Equal(t, len(Entities), c)
+		var entities []*Entity4Test
+		_, err = e.GetAll(context.Background(), New(), &entities)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"executor:count", "executor:count", "executor:list"}, calls)
+	}
+}
+
+func TestLoggingInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	b := New("Int1", "Email").Eq("Email", "alice@example.com").Gte("Int1", 2).Limit(10)
+	b.Use(LoggingInterceptor(logger, "Email"))
+	b.Build(datastore.NewQuery(Kind4Test))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Equal(t, 2, len(lines)) {
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+		assert.Equal(t, "querybuilder: build", entry["msg"])
+		assert.Equal(t, "list", entry["mode"])
+		assert.Equal(t, `[{"field":"Email","ope":"=","value":"[REDACTED]"},{"field":"Int1","ope":">=","value":2}]`, entry["conditions"])
+		assert.Equal(t, []interface{}{"Int1"}, entry["sort_fields"])
+		assert.Equal(t, []interface{}{"Int1"}, entry["fields"])
+		assert.Equal(t, float64(10), entry["limit"])
+	}
+	assert.NotContains(t, buf.String(), "alice@example.com")
+	assert.Equal(t, "alice@example.com", b.Conditions[0].Value)
+}
@@ -0,0 +1,193 @@
+//go:build go1.23
+
+package querybuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+
+	"cloud.google.com/go/datastore"
+)
+
+// All streams entities for qb with Assigns applied. Projection rows are
+// merged by key as Pages does.
+func All[T any](ctx context.Context, e *Executor, qb *QueryBuilder) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		for page, err := range Pages[T](ctx, e, qb, 0) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, entity := range page {
+				if !yield(entity, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Pages streams entities for qb by pages of pageSize.
+// DefaultBatchSize is used if pageSize is not positive.
+func Pages[T any](ctx context.Context, e *Executor, qb *QueryBuilder, pageSize int) iter.Seq2[[]*T, error] {
+	return func(yield func([]*T, error) bool) {
+		if pageSize < 1 {
+			pageSize = DefaultBatchSize
+		}
+		qb, err := e.Prepare(qb)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// Geo filter and client side conditions require all the results
+		if qb.Geo != nil || len(qb.ClientConditions()) > 0 {
+			var entities []*T
+			_, err := e.getAll(ctx, qb, &entities)
+			if err != nil && !errors.Is(err, ErrMaxScanExceeded) {
+				yield(nil, err)
+				return
+			}
+			for len(entities) > 0 {
+				n := min(pageSize, len(entities))
+				if !yield(entities[:n], nil) {
+					return
+				}
+				entities = entities[n:]
+			}
+			if err != nil { // after the entities matched before MaxScan
+				yield(nil, err)
+			}
+			return
+		}
+
+		r, assigns := e.newListReader(qb, false)
+		merge := len(qb.ProjectFields()) > 0
+		keys := []*datastore.Key{}
+		rows := []*T{}
+		for {
+			n := pageEnd(keys, pageSize, merge)
+			for n < 0 && !r.done {
+				var batch []*T
+				batchKeys, err := e.readBatch(ctx, "querybuilder.Pages", qb, r, pageSize, &batch)
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				keys = append(keys, batchKeys...)
+				rows = append(rows, batch...)
+				n = pageEnd(keys, pageSize, merge)
+			}
+			if n < 0 {
+				n = len(keys)
+			}
+			if n == 0 {
+				return
+			}
+			pageKeys, page := keys[:n:n], rows[:n:n]
+			keys, rows = keys[n:], rows[n:]
+			if _, err := e.postProcess(ctx, qb, assigns, pageKeys, &page); err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(page, nil) {
+				return
+			}
+		}
+	}
+}
+
+// pageEnd returns the number of rows for the page of pageSize entities,
+// or -1 if more rows are required. Projection queries return a row for
+// each value of multi-valued properties, so the rows of the last entity
+// are complete only when the row of the next entity is read.
+func pageEnd(keys []*datastore.Key, pageSize int, merge bool) int {
+	if !merge {
+		if len(keys) < pageSize {
+			return -1
+		}
+		return pageSize
+	}
+	entities := 0
+	for i, key := range keys {
+		if i > 0 && key.Equal(keys[i-1]) {
+			continue
+		}
+		if entities == pageSize {
+			return i
+		}
+		entities++
+	}
+	return -1
+}
+
+// Keys streams keys for qb.
+func Keys(ctx context.Context, e *Executor, qb *QueryBuilder) iter.Seq2[*datastore.Key, error] {
+	return func(yield func(*datastore.Key, error) bool) {
+		qb, err := e.Prepare(qb)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if qb.Geo != nil || len(qb.ClientConditions()) > 0 {
+			yield(nil, fmt.Errorf("Keys doesn't support geo filter nor conditions evaluated on client"))
+			return
+		}
+		qb = qb.Clone()
+		qb.Fields = nil
+
+		r, _ := e.newListReader(qb, true)
+		for !r.done {
+			keys, err := e.readBatch(ctx, "querybuilder.Keys", qb, r, DefaultBatchSize, nil)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, key := range keys {
+				if !yield(key, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// readBatch reads a batch of r with Telemetry like getAll.
+func (e *Executor) readBatch(ctx context.Context, name string, qb *QueryBuilder, r *batchReader, size int, dst interface{}) ([]*datastore.Key, error) {
+	var keys []*datastore.Key
+	err := e.Telemetry.observe(ctx, name, e, qb, func(ctx context.Context) (int, error) {
+		var err error
+		keys, err = r.next(ctx, size, dst)
+		return len(keys), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// newListReader returns the batchReader for the results of qb within its
+// offset and limit, and the assigns for them.
+func (e *Executor) newListReader(qb *QueryBuilder, keysOnly bool) (*batchReader, Assigners) {
+	offset, _ := qb.IntFilterValue("offset")
+	limit, ok := qb.IntFilterValue("limit")
+	if !ok {
+		limit = -1
+	}
+	q, assigns := qb.WithoutFilters().Build(e.NewQuery())
+	if keysOnly {
+		q = q.KeysOnly()
+	}
+	return newBatchReader(e.Client, q, offset, limit), assigns
+}
+
+func (b *TypedBuilder[T]) All(ctx context.Context, e *Executor) iter.Seq2[*T, error] {
+	if b.err != nil {
+		return func(yield func(*T, error) bool) {
+			yield(nil, b.err)
+		}
+	}
+	return All[T](ctx, e, b.Builder)
+}
@@ -0,0 +1,194 @@
+//go:build go1.23
+
+package querybuilder
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akm/querybuilder/testsupport"
+)
+
+func TestIterators(t *testing.T) {
+	ctx := context.Background()
+
+	{
+		cli := &fakeClient{entities: Entities}
+		e := NewExecutor(cli, Kind4Test)
+		int1s := []int{}
+		for entity, err := range All[Entity4Test](ctx, e, New("Int1", "Int2").Eq("Int2", 9)) {
+			assert.NoError(t, err)
+			assert.Equal(t, 9, entity.Int2) // assigned
+			int1s = append(int1s, entity.Int1)
+		}
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, int1s)
+		assert.Equal(t, 1, cli.getAllCalls) // Less than DefaultBatchSize
+	}
+
+	{ // Break
+		cli := &fakeClient{entities: Entities, pageSize: 2}
+		e := NewExecutor(cli, Kind4Test)
+		int1s := []int{}
+		for page, err := range Pages[Entity4Test](ctx, e, New(), 2) {
+			assert.NoError(t, err)
+			assert.Equal(t, 2, len(page))
+			for _, entity := range page {
+				int1s = append(int1s, entity.Int1)
+			}
+			if len(int1s) >= 4 {
+				break
+			}
+		}
+		assert.Equal(t, []int{1, 2, 3, 4}, int1s)
+		assert.Equal(t, 2, cli.getAllCalls)
+	}
+
+	{ // Pages until the end
+		cli := &fakeClient{entities: Entities, pageSize: 4}
+		e := NewExecutor(cli, Kind4Test)
+		sizes := []int{}
+		for page, err := range Pages[Entity4Test](ctx, e, New(), 4) {
+			assert.NoError(t, err)
+			sizes = append(sizes, len(page))
+		}
+		assert.Equal(t, []int{4, 2}, sizes)
+		assert.Equal(t, 2, cli.getAllCalls)
+	}
+
+	{ // Projection rows of an entity are not split into pages
+		rows := []*Entity4Test{{Int1: 1}, {Int1: 1}, {Int1: 2}, {Int1: 2}, {Int1: 2}, {Int1: 3}}
+		cli := &fakeClient{entities: rows, keyIDs: []int64{1, 1, 2, 2, 2, 3}, pageSize: 2}
+		e := NewExecutor(cli, Kind4Test)
+		pages := [][]int{}
+		for page, err := range Pages[Entity4Test](ctx, e, New("Int1", "Str1"), 2) {
+			assert.NoError(t, err)
+			int1s := []int{}
+			for _, entity := range page {
+				int1s = append(int1s, entity.Int1)
+			}
+			pages = append(pages, int1s)
+		}
+		assert.Equal(t, [][]int{{1, 2}, {3}}, pages)
+		assert.Equal(t, 4, cli.getAllCalls)
+	}
+
+	{ // Keys
+		e := NewExecutor(&fakeClient{entities: Entities}, Kind4Test)
+		keys := []*datastore.Key{}
+		for key, err := range Keys(ctx, e, New("Int1")) {
+			assert.NoError(t, err)
+			keys = append(keys, key)
+			if len(keys) == 3 {
+				break
+			}
+		}
+		assert.Equal(t, []*datastore.Key{
+			datastore.IDKey(Kind4Test, 1, nil),
+			datastore.IDKey(Kind4Test, 2, nil),
+			datastore.IDKey(Kind4Test, 3, nil),
+		}, keys)
+	}
+
+	{ // Client side conditions
+		e := NewExecutor(&fakeClient{entities: Entities}, Kind4Test)
+		int1s := []int{}
+		for entity, err := range All[Entity4Test](ctx, e, New().Gt("Int1", 4).Gt("Int2", 0).EvaluateIneqOnClient(0)) {
+			assert.NoError(t, err)
+			int1s = append(int1s, entity.Int1)
+		}
+		assert.Equal(t, []int{5, 6}, int1s)
+	}
+
+	{ // Errors
+		e := NewExecutor(&fakeClient{entities: Entities}, Kind4Test)
+		count := 0
+		for entity, err := range All[Entity4Test](ctx, e, New().Eq("Int1", Placeholder("x"))) {
+			assert.Nil(t, entity)
+			assert.Error(t, err)
+			count++
+		}
+		assert.Equal(t, 1, count)
+	}
+}
+
+func TestBuilderIterators(t *testing.T) {
+	testsupport.WithAEContext(t, func(ctx context.Context) error {
+		cli, err := datastore.NewClient(ctx, "")
+		assert.NoError(t, err)
+
+		DeleteAll(t, ctx, cli, Kind4Test)
+		{
+			keys := make([]*datastore.Key, len(Entities))
+			for i := range keys {
+				keys[i] = datastore.IncompleteKey(Kind4Test, nil)
+			}
+			_, err := cli.PutMulti(ctx, keys, Entities)
+			assert.NoError(t, err)
+		}
+
+		e := NewExecutor(cli, Kind4Test)
+
+		{
+			b := New("Int1", "Int2", "Str1").Eq("Int2", 1).Asc("Int1")
+			int1s := []int{}
+			for entity, err := range All[Entity4Test](ctx, e, b) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, entity.Int2)
+				int1s = append(int1s, entity.Int1)
+			}
+			assert.Equal(t, []int{1, 2}, int1s)
+		}
+
+		{
+			b := New().Asc("Int1").Offset(1).Limit(4)
+			sizes := []int{}
+			for page, err := range Pages[Entity4Test](ctx, e, b, 3) {
+				assert.NoError(t, err)
+				sizes = append(sizes, len(page))
+			}
+			assert.Equal(t, []int{3, 1}, sizes)
+		}
+
+		{
+			count := 0
+			for _, err := range Keys(ctx, e, New().Gte("Int1", 3)) {
+				assert.NoError(t, err)
+				count++
+			}
+			assert.Equal(t, 4, count)
+		}
+		return nil
+	})
+}
+
+func TestIteratorsTelemetry(t *testing.T) {
+	ctx := context.Background()
+	exporter := tracetest.NewInMemoryExporter()
+	telemetry, err := NewTelemetry(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), nil)
+	assert.NoError(t, err)
+
+	e := NewExecutor(&fakeClient{entities: Entities}, Kind4Test)
+	e.Telemetry = telemetry
+	for _, err := range Pages[Entity4Test](ctx, e, New(), 4) {
+		assert.NoError(t, err)
+	}
+	for _, err := range Keys(ctx, e, New()) {
+		assert.NoError(t, err)
+	}
+
+	names := []string{}
+	for _, s := range exporter.GetSpans() {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{
+		"querybuilder.Pages", "querybuilder.Assign",
+		"querybuilder.Pages", "querybuilder.Assign",
+		"querybuilder.Keys",
+	}, names)
+}
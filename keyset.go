@@ -0,0 +1,230 @@
+package querybuilder
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"cloud.google.com/go/datastore"
+)
+
+// Row has the values of the sort fields of an entity by the property names.
+// The key is given by KeyField.
+type Row map[string]interface{}
+
+// RowOf returns the Row of entity for the sort fields of qb and the key.
+func (qb *QueryBuilder) RowOf(entity interface{}, key *datastore.Key) (Row, error) {
+	v := reflect.ValueOf(entity)
+	t := v.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	r := Row{KeyField: key}
+	for _, s := range qb.SortFields {
+		field := strings.TrimPrefix(s, "-")
+		if field == KeyField {
+			continue
+		}
+		path, _, err := ResolveField(t, field)
+		if err != nil {
+			return nil, err
+		}
+		values := []interface{}{}
+		err = ReflectWalkIn(&v, path, ".", func(fv *reflect.Value) error {
+			if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+				for i := 0; i < fv.Len(); i++ {
+					values = append(values, fv.Index(i).Interface())
+				}
+				return nil
+			}
+			values = append(values, fv.Interface())
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != 1 {
+			return nil, fmt.Errorf("%s has %d values but must have a value to be sorted by", field, len(values))
+		}
+		r[field] = values[0]
+	}
+	return r, nil
+}
+
+// compareRows compares rows in the order of sortFields.
+func compareRows(sortFields Strings, a, b Row) (int, error) {
+	for _, s := range sortFields {
+		field := strings.TrimPrefix(s, "-")
+		c, err := CompareValues(a[field], b[field])
+		if err != nil {
+			return 0, err
+		}
+		if c != 0 {
+			if strings.HasPrefix(s, "-") {
+				return -c, nil
+			}
+			return c, nil
+		}
+	}
+	return 0, nil
+}
+
+// TieBreakByKey lets the query be ordered by the key after the sort fields,
+// so that entities with the same values of them don't move between pages.
+func (qb *QueryBuilder) TieBreakByKey() *QueryBuilder {
+	qb.KeyTieBreaker = true
+	return qb
+}
+
+// ListSortFields returns the sort orders of the query. The key is appended
+// to SortFields if KeyTieBreaker is true.
+func (qb *QueryBuilder) ListSortFields() Strings {
+	if qb.KeyTieBreaker && len(qb.SortFields) > 0 {
+		return qb.withKeySort()
+	}
+	return qb.SortFields
+}
+
+// withKeySort returns the sort fields with the key at the end in the
+// direction of the last one.
+func (qb *QueryBuilder) withKeySort() Strings {
+	r := append(Strings{}, qb.SortFields...)
+	if len(r) > 0 && strings.TrimPrefix(r[len(r)-1], "-") == KeyField {
+		return r
+	}
+	if len(r) > 0 && strings.HasPrefix(r[len(r)-1], "-") {
+		return append(r, "-"+KeyField)
+	}
+	return append(r, KeyField)
+}
+
+// After returns the builders for the entities after row in the order of
+// the sort fields and the key. Datastore can't query the disjunction
+// (s1 > v1) OR (s1 = v1 AND s2 > v2) OR ..., so it's expanded to a builder
+// for each term which has only one inequality field. The concatenation of
+// their results in the returned order is in the order of qb.
+// The offset and the cursor of qb are dropped. The values of row are
+// assigned to the struct fields named by the properties. Use AfterEntity
+// for properties renamed by datastore tags.
+func (qb *QueryBuilder) After(row Row) ([]*QueryBuilder, error) {
+	return qb.keyset(row, nil, false)
+}
+
+// Before returns the builders for the entities before row like After.
+// Their sort orders are reversed, so the concatenation of their results
+// must be reversed to get the entities in the order of qb.
+func (qb *QueryBuilder) Before(row Row) ([]*QueryBuilder, error) {
+	return qb.keyset(row, nil, true)
+}
+
+// AfterEntity works like After with the row of entity, whose type gives
+// the paths of the struct fields to assign the values to.
+func (qb *QueryBuilder) AfterEntity(entity interface{}, key *datastore.Key) ([]*QueryBuilder, error) {
+	row, err := qb.RowOf(entity, key)
+	if err != nil {
+		return nil, err
+	}
+	return qb.keyset(row, reflect.TypeOf(entity), false)
+}
+
+// BeforeEntity works like Before with the row of entity as AfterEntity.
+func (qb *QueryBuilder) BeforeEntity(entity interface{}, key *datastore.Key) ([]*QueryBuilder, error) {
+	row, err := qb.RowOf(entity, key)
+	if err != nil {
+		return nil, err
+	}
+	return qb.keyset(row, reflect.TypeOf(entity), true)
+}
+
+// keyset resolves the paths of the fields in t if it's not nil.
+func (qb *QueryBuilder) keyset(row Row, t reflect.Type, reverse bool) ([]*QueryBuilder, error) {
+	sorts := qb.withKeySort()
+	fields := Strings{}
+	paths := map[string]string{}
+	for _, s := range sorts {
+		field := strings.TrimPrefix(s, "-")
+		if _, ok := row[field]; !ok {
+			return nil, fmt.Errorf("Row has no value of %s", field)
+		}
+		fields = append(fields, field)
+		paths[field] = field
+		if t != nil && field != KeyField {
+			path, _, err := ResolveField(t, field)
+			if err != nil {
+				return nil, err
+			}
+			paths[field] = path
+		}
+	}
+	if reverse {
+		for i, s := range sorts {
+			if strings.HasPrefix(s, "-") {
+				sorts[i] = s[1:]
+			} else {
+				sorts[i] = "-" + s
+			}
+		}
+	}
+
+	r := []*QueryBuilder{}
+	for i := len(sorts) - 1; i >= 0; i-- {
+		b := qb.Clone()
+		b.Cursor = ""
+		b.Filters = []*ValuedFilter{}
+		for _, f := range qb.Filters {
+			if f.Name != "offset" {
+				b.Filters = append(b.Filters, f)
+			}
+		}
+		// Inequalities on the fields fixed by equalities are satisfied by row
+		b.Conditions = qb.Conditions.Select(func(c *Condition) bool {
+			return c.Ope == EQ || !fields[:i].Has(c.Field)
+		})
+		for _, field := range fields[:i] {
+			b.EqWithPath(field, paths[field], row[field]) // Ignored in projection and assigned
+		}
+		ope := GT
+		if strings.HasPrefix(sorts[i], "-") {
+			ope = LT
+		}
+		b.AddCondition(fields[i], ope, row[fields[i]])
+		b.SortFields = append(Strings{}, sorts[i:]...)
+		r = append(r, b)
+	}
+	return r, nil
+}
+
+// GetAllInOrder runs builders in order and appends their results to dst
+// until the limit of the first builder.
+func (e *Executor) GetAllInOrder(ctx context.Context, builders []*QueryBuilder, dst interface{}) ([]*datastore.Key, error) {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Unsupported type of destination %T", dst)
+	}
+	r := []*datastore.Key{}
+	if len(builders) == 0 {
+		return r, nil
+	}
+	limit, hasLimit := builders[0].IntFilterValue("limit")
+	for _, b := range builders {
+		if hasLimit {
+			if len(r) >= limit {
+				break
+			}
+			b = b.Clone().Limit(limit - len(r))
+		}
+		page := reflect.New(dv.Elem().Type())
+		keys, err := e.GetAll(ctx, b, page.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if hasLimit && len(r)+len(keys) > limit {
+			keys = keys[:limit-len(r)]
+			page.Elem().SetLen(len(keys))
+		}
+		dv.Elem().Set(reflect.AppendSlice(dv.Elem(), page.Elem()))
+		r = append(r, keys...)
+	}
+	return r, nil
+}
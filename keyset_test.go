@@ -0,0 +1,109 @@
+package querybuilder
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyset(t *testing.T) {
+	key := datastore.IDKey(Kind4Test, 3, nil)
+
+	{
+		b := New().Eq("Str1", "a").Asc("Int2").Offset(10).Limit(5)
+		row, err := b.RowOf(Entities[2], key)
+		assert.NoError(t, err)
+		assert.Equal(t, Row{"Int2": 2, KeyField: key}, row)
+
+		bs, err := b.After(row)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(bs))
+		assert.Equal(t, Conditions{{"Str1", EQ, "a"}, {"Int2", EQ, 2}, {KeyField, GT, key}}, bs[0].Conditions)
+		assert.Equal(t, Strings{KeyField}, bs[0].SortFields)
+		assert.Equal(t, Strings{"Str1", "Int2"}, bs[0].Ignored)
+		assert.Equal(t, Conditions{{"Str1", EQ, "a"}, {"Int2", GT, 2}}, bs[1].Conditions)
+		assert.Equal(t, Strings{"Int2", KeyField}, bs[1].SortFields)
+		for _, i := range bs {
+			assert.Equal(t, []*ValuedFilter{{Name: "limit", IntValue: 5}}, i.Filters)
+		}
+		// qb is not changed
+		assert.Equal(t, Conditions{{"Str1", EQ, "a"}}, b.Conditions)
+	}
+
+	{ // Descending and the existing inequality
+		b := New().Gte("Int1", 2).Desc("Str2")
+		assert.Equal(t, Strings{"Int1", "-Str2"}, b.SortFields)
+		row := Row{"Int1": 3, "Str2": "baz", KeyField: key}
+
+		bs, err := b.After(row)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(bs))
+		assert.Equal(t, Conditions{{"Int1", EQ, 3}, {"Str2", EQ, "baz"}, {KeyField, LT, key}}, bs[0].Conditions)
+		assert.Equal(t, Strings{"-" + KeyField}, bs[0].SortFields)
+		assert.Equal(t, Conditions{{"Int1", EQ, 3}, {"Str2", LT, "baz"}}, bs[1].Conditions)
+		assert.Equal(t, Strings{"-Str2", "-" + KeyField}, bs[1].SortFields)
+		assert.Equal(t, Conditions{{"Int1", GTE, 2}, {"Int1", GT, 3}}, bs[2].Conditions)
+		assert.Equal(t, Strings{"Int1", "-Str2", "-" + KeyField}, bs[2].SortFields)
+		for _, i := range bs {
+			assert.False(t, i.Conditions.HasMultipleIneqFields())
+		}
+
+		bs, err = b.Before(row)
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{{"Int1", EQ, 3}, {"Str2", EQ, "baz"}, {KeyField, GT, key}}, bs[0].Conditions)
+		assert.Equal(t, Strings{KeyField}, bs[0].SortFields)
+		assert.Equal(t, Conditions{{"Int1", GTE, 2}, {"Int1", LT, 3}}, bs[2].Conditions)
+		assert.Equal(t, Strings{"-Int1", "Str2", KeyField}, bs[2].SortFields)
+	}
+
+	{ // Only key
+		bs, err := New().After(Row{KeyField: key})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(bs))
+		assert.Equal(t, Conditions{{KeyField, GT, key}}, bs[0].Conditions)
+	}
+
+	{ // Properties renamed by datastore tags
+		type named struct {
+			Name string `datastore:"name"`
+			Age  int    `datastore:"age"`
+		}
+		b := New("name", "age").Asc("name")
+		bs, err := b.AfterEntity(&named{Name: "x", Age: 1}, key)
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{{"name", EQ, "x"}, {KeyField, GT, key}}, bs[0].Conditions)
+		assert.Equal(t, Strings{"name"}, bs[0].Ignored)
+		page := []*named{{Age: 2}}
+		assert.NoError(t, bs[0].Assigns.AssignAll(page))
+		assert.Equal(t, "x", page[0].Name)
+
+		bs, err = b.BeforeEntity(named{Name: "x"}, key)
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{{"name", EQ, "x"}, {KeyField, LT, key}}, bs[0].Conditions)
+	}
+
+	{
+		_, err := New().Asc("Int1").After(Row{KeyField: key})
+		assert.Error(t, err)
+		_, err = New().Asc("Strings").RowOf(ComplicatedEntities[2], key)
+		assert.Error(t, err)
+	}
+}
+
+func TestGetAllInOrder(t *testing.T) {
+	ctx := context.Background()
+	cli := &fakeClient{entities: Entities, pageSize: 2}
+	e := NewExecutor(cli, Kind4Test)
+	bs, err := New().Asc("Int2").Limit(3).After(Row{"Int2": 1, KeyField: datastore.IDKey(Kind4Test, 1, nil)})
+	assert.NoError(t, err)
+
+	var entities []*Entity4Test
+	keys, err := e.GetAllInOrder(ctx, bs, &entities)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(keys))
+	assert.Equal(t, 3, len(entities))
+	assert.Equal(t, 2, cli.getAllCalls)
+}
@@ -0,0 +1,62 @@
+package querybuilder
+
+import (
+	"context"
+	"log/slog"
+
+	"cloud.google.com/go/datastore"
+)
+
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns a copy of qb whose values for fields are replaced
+// with RedactedValue.
+func (qb *QueryBuilder) Redacted(fields ...string) *QueryBuilder {
+	r := qb.Clone()
+	targets := Strings(fields)
+	for i, c := range r.Conditions {
+		if targets.Has(c.Field) {
+			r.Conditions[i] = &Condition{Field: c.Field, Ope: c.Ope, Value: RedactedValue}
+		}
+	}
+	for i, a := range r.Assigns {
+		if targets.Has(a.Field) {
+			r.Assigns[i] = &Assigner{Field: a.Field, Value: RedactedValue}
+		}
+	}
+	return r
+}
+
+// LoggingInterceptor logs the builder with the values of redacted
+// fields hidden. slog.Default() is used if logger is nil.
+func LoggingInterceptor(logger *slog.Logger, redacted ...string) Interceptor {
+	return func(next BuildFunc) BuildFunc {
+		return func(mode BuildMode, qb *QueryBuilder, q *datastore.Query) (*datastore.Query, Assigners) {
+			q, assigns := next(mode, qb, q)
+			l := logger
+			if l == nil {
+				l = slog.Default()
+			}
+			conditions, err := marshalJSON(qb.Redacted(redacted...).Conditions)
+			if err != nil {
+				conditions = []byte(err.Error())
+			}
+			attrs := []slog.Attr{
+				slog.String("mode", mode.String()),
+				slog.String("conditions", string(conditions)),
+			}
+			if mode == ListMode {
+				offset, _ := qb.IntFilterValue("offset")
+				limit, _ := qb.IntFilterValue("limit")
+				attrs = append(attrs,
+					slog.Any("sort_fields", []string(qb.ListSortFields())),
+					slog.Any("fields", []string(qb.ProjectFields())),
+					slog.Int("offset", offset),
+					slog.Int("limit", limit),
+				)
+			}
+			l.LogAttrs(context.Background(), slog.LevelInfo, "querybuilder: build", attrs...)
+			return q, assigns
+		}
+	}
+}
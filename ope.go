@@ -24,3 +24,20 @@ func BuildOperatorMap(opes []Ope) map[string]Ope {
 	}
 	return r
 }
+
+func (ope Ope) Match(cmp int) bool {
+	switch ope {
+	case LT:
+		return cmp < 0
+	case LTE:
+		return cmp <= 0
+	case GT:
+		return cmp > 0
+	case GTE:
+		return cmp >= 0
+	case EQ:
+		return cmp == 0
+	default:
+		return false
+	}
+}
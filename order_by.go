@@ -0,0 +1,79 @@
+package querybuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseOrderBy parses order_by of AIP-132 (https://google.aip.dev/132#ordering)
+// like "Int1 desc, Str1" into sort fields like "-Int1" and "Str1".
+// All the fields are allowed if allowed is empty.
+func ParseOrderBy(orderBy string, allowed ...string) (Strings, error) {
+	r := Strings{}
+	if strings.TrimSpace(orderBy) == "" {
+		return r, nil
+	}
+	pos := 0
+	for _, part := range strings.Split(orderBy, ",") {
+		words := strings.Fields(part)
+		start := pos + len(part) - len(strings.TrimLeft(part, " \t\n\r"))
+		pos += len(part) + 1
+		if len(words) == 0 {
+			return nil, fmt.Errorf("Invalid order_by at %d: field expected", start)
+		}
+		field := words[0]
+		if !filterFieldPattern.MatchString(field) {
+			return nil, fmt.Errorf("Invalid order_by at %d: invalid field %q", start, field)
+		}
+		if len(allowed) > 0 && !Strings(allowed).Has(field) {
+			return nil, fmt.Errorf("Invalid order_by at %d: field %s is not allowed", start, field)
+		}
+		if r.Has(field) || r.Has("-"+field) {
+			return nil, fmt.Errorf("Invalid order_by at %d: field %s is duplicated", start, field)
+		}
+		switch {
+		case len(words) == 1:
+			r = append(r, field)
+		case len(words) == 2 && words[1] == "desc":
+			r = append(r, "-"+field)
+		case len(words) == 2 && words[1] == "asc":
+			r = append(r, field)
+		default:
+			return nil, fmt.Errorf("Invalid order_by at %d: unexpected %q", start, strings.Join(words[1:], " "))
+		}
+	}
+	return r, nil
+}
+
+// OrderBy adds the sort fields of orderBy to qb. The sort field which Ineq
+// adds for the inequality filter stays first unless orderBy starts with the
+// field, because Datastore requires it. orderBy can't have the field
+// elsewhere.
+func (qb *QueryBuilder) OrderBy(orderBy string, allowed ...string) (*QueryBuilder, error) {
+	sorts, err := ParseOrderBy(orderBy, allowed...)
+	if err != nil {
+		return nil, err
+	}
+	if len(sorts) == 0 {
+		return qb, nil
+	}
+	ineq := qb.ServerIneqField()
+	for i, s := range sorts {
+		if i > 0 && strings.TrimPrefix(s, "-") == ineq {
+			return nil, fmt.Errorf("Invalid order_by: %s must be the first because of the inequality filter", ineq)
+		}
+	}
+	fields := Strings{}
+	for _, s := range sorts {
+		fields = append(fields, strings.TrimPrefix(s, "-"))
+	}
+	rest := qb.SortFields.Filter(func(_ Strings, s string) bool {
+		return !fields.Has(strings.TrimPrefix(s, "-"))
+	})
+	if strings.TrimPrefix(sorts[0], "-") == ineq {
+		qb.SortFields = append(sorts, rest...)
+	} else {
+		qb.SortFields = append(rest, sorts...)
+	}
+	return qb, nil
+}
@@ -0,0 +1,60 @@
+package querybuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOrderBy(t *testing.T) {
+	{
+		r, err := ParseOrderBy("Int1 desc, Str1,Sub1.I1 asc")
+		assert.NoError(t, err)
+		assert.Equal(t, Strings{"-Int1", "Str1", "Sub1.I1"}, r)
+	}
+	{
+		r, err := ParseOrderBy(" ")
+		assert.NoError(t, err)
+		assert.Equal(t, Strings{}, r)
+	}
+	for _, orderBy := range []string{
+		"Int1 descending",
+		"Int1 desc desc",
+		"Int1,",
+		"Int1, Int1 desc",
+		"1abc",
+		"Int1, Str2",
+	} {
+		_, err := ParseOrderBy(orderBy, "Int1", "Str1")
+		assert.Error(t, err, orderBy)
+	}
+	{
+		_, err := ParseOrderBy("Int1, Str2", "Int1", "Str1")
+		assert.EqualError(t, err, "Invalid order_by at 6: field Str2 is not allowed")
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	{
+		b, err := New().OrderBy("Int1 desc, Str1")
+		assert.NoError(t, err)
+		assert.Equal(t, Strings{"-Int1", "Str1"}, b.SortFields)
+	}
+
+	{ // The sort field of the inequality filter stays first
+		b, err := New().Gte("Int1", 2).OrderBy("Str1 desc")
+		assert.NoError(t, err)
+		assert.Equal(t, Strings{"Int1", "-Str1"}, b.SortFields)
+	}
+
+	{ // The direction of the inequality field can be changed
+		b, err := New().Gte("Int1", 2).OrderBy("Int1 desc, Str1")
+		assert.NoError(t, err)
+		assert.Equal(t, Strings{"-Int1", "Str1"}, b.SortFields)
+	}
+
+	{
+		_, err := New().Gte("Int1", 2).OrderBy("Str1, Int1")
+		assert.Error(t, err)
+	}
+}
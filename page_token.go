@@ -0,0 +1,119 @@
+package querybuilder
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+)
+
+var ErrPageTokenMismatch = errors.New("Page token is for another query")
+
+// PageToken is the page_token of AIP-158 list methods. It has the cursor to
+// start the next page and the fingerprint of the query not to be used for
+// another query.
+type PageToken struct {
+	Cursor      string `json:"c"`
+	Offset      int    `json:"o,omitempty"`
+	Fingerprint string `json:"f"`
+}
+
+// PageFingerprint returns the hash of the conditions and the sort fields,
+// which don't change between the pages.
+func (qb *QueryBuilder) PageFingerprint() (string, error) {
+	b, err := json.Marshal(&QueryBuilder{Conditions: qb.Conditions, SortFields: qb.ListSortFields()})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// NextPageToken returns the encoded page token to get the page starting at
+// cursor, which is given by datastore.Iterator.
+func (qb *QueryBuilder) NextPageToken(cursor datastore.Cursor) (string, error) {
+	fp, err := qb.PageFingerprint()
+	if err != nil {
+		return "", err
+	}
+	return (&PageToken{Cursor: cursor.String(), Fingerprint: fp}).Encode()
+}
+
+func (t *PageToken) Encode() (string, error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func DecodePageToken(s string) (*PageToken, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid page token: %v", err)
+	}
+	t := &PageToken{}
+	if err := json.Unmarshal(b, t); err != nil {
+		return nil, fmt.Errorf("Invalid page token: %v", err)
+	}
+	if _, err := datastore.DecodeCursor(t.Cursor); err != nil {
+		return nil, fmt.Errorf("Invalid page token: %v", err)
+	}
+	return t, nil
+}
+
+func (t *PageToken) Verify(qb *QueryBuilder) error {
+	fp, err := qb.PageFingerprint()
+	if err != nil {
+		return err
+	}
+	if fp != t.Fingerprint {
+		return ErrPageTokenMismatch
+	}
+	return nil
+}
+
+// Page sets the limit by pageSize and the cursor by pageToken to qb.
+// pageToken must be given by NextPageToken of the builder with the same
+// conditions and sort fields. The first page is given by an empty token.
+func (qb *QueryBuilder) Page(pageSize int, pageToken string) (*QueryBuilder, error) {
+	if pageToken != "" {
+		t, err := DecodePageToken(pageToken)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.Verify(qb); err != nil {
+			return nil, err
+		}
+		if err := validateCursor(t.Cursor); err != nil {
+			return nil, err
+		}
+		qb.Cursor = t.Cursor
+	}
+	if pageSize > 0 {
+		qb.Limit(pageSize)
+	}
+	return qb, nil
+}
+
+// validateCursor returns an error if cursor is neither empty nor a cursor
+// given by datastore.Cursor.String.
+func validateCursor(cursor string) error {
+	if cursor == "" {
+		return nil
+	}
+	if _, err := datastore.DecodeCursor(cursor); err != nil {
+		return fmt.Errorf("Invalid cursor: %v", err)
+	}
+	return nil
+}
+
+// StartAt lets the query start at cursor.
+func (qb *QueryBuilder) StartAt(cursor datastore.Cursor) *QueryBuilder {
+	qb.Cursor = cursor.String()
+	return qb
+}
@@ -0,0 +1,252 @@
+package querybuilder
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+var (
+	ErrPageTokenInvalid  = errors.New("Invalid page token")
+	ErrPageTokenExpired  = errors.New("Page token expired")
+	ErrPageTokenUnsigned = errors.New("Paging must be given by a signed page token")
+)
+
+const (
+	pageTokenVersion   = 1
+	pageTokenEncrypted = 1
+	pageTokenMACSize   = 16
+	pageTokenHeader    = 3 // version, key ID and flags
+)
+
+// PageTokenKey is a secret to sign page tokens. ID is written in tokens to
+// find the key to verify them.
+type PageTokenKey struct {
+	ID     byte
+	Secret []byte
+}
+
+func (k *PageTokenKey) derive(purpose string) []byte {
+	mac := hmac.New(sha256.New, k.Secret)
+	mac.Write([]byte("querybuilder page token " + purpose))
+	return mac.Sum(nil)
+}
+
+// PageTokenCodec encodes PageToken into a compact binary form signed by
+// HMAC-SHA256, or encrypted by AES-GCM if Encrypt is true.
+// Keys[0] is used to encode and all of Keys are used to decode, so that
+// keys can be rotated by adding a new key at the head and removing the old
+// one after TTL.
+type PageTokenCodec struct {
+	Keys    []*PageTokenKey
+	Encrypt bool
+	TTL     time.Duration // Tokens don't expire if it's 0
+	Now     func() time.Time
+}
+
+func NewPageTokenCodec(keys ...*PageTokenKey) *PageTokenCodec {
+	return &PageTokenCodec{Keys: keys, Now: time.Now}
+}
+
+func (c *PageTokenCodec) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+func (c *PageTokenCodec) key(id byte) *PageTokenKey {
+	for _, k := range c.Keys {
+		if k.ID == id {
+			return k
+		}
+	}
+	return nil
+}
+
+func (c *PageTokenCodec) Encode(t *PageToken) (string, error) {
+	if len(c.Keys) == 0 {
+		return "", fmt.Errorf("No key to sign page tokens")
+	}
+	key := c.Keys[0]
+	fp, err := hex.DecodeString(t.Fingerprint)
+	if err != nil || len(fp) != sha256.Size {
+		return "", fmt.Errorf("Invalid fingerprint: %q", t.Fingerprint)
+	}
+	cursor, err := base64.RawURLEncoding.DecodeString(t.Cursor)
+	if err != nil {
+		return "", fmt.Errorf("Invalid cursor: %v", err)
+	}
+
+	var expiresAt int64
+	if c.TTL > 0 {
+		expiresAt = c.now().Add(c.TTL).Unix()
+	}
+	body := binary.AppendUvarint(nil, uint64(expiresAt))
+	body = binary.AppendUvarint(body, uint64(t.Offset))
+	body = append(body, fp...)
+	body = append(body, cursor...)
+
+	header := []byte{pageTokenVersion, key.ID, 0}
+	var r []byte
+	if c.Encrypt {
+		header[2] |= pageTokenEncrypted
+		aead, err := newPageTokenAEAD(key)
+		if err != nil {
+			return "", err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return "", err
+		}
+		r = append(append(header, nonce...), aead.Seal(nil, nonce, body, header)...)
+	} else {
+		r = append(header, body...)
+		r = append(r, pageTokenMAC(key, r)...)
+	}
+	return base64.RawURLEncoding.EncodeToString(r), nil
+}
+
+func (c *PageTokenCodec) Decode(s string) (*PageToken, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) < pageTokenHeader || b[0] != pageTokenVersion {
+		return nil, ErrPageTokenInvalid
+	}
+	key := c.key(b[1])
+	if key == nil {
+		return nil, fmt.Errorf("%w: unknown key %d", ErrPageTokenInvalid, b[1])
+	}
+	header := b[:pageTokenHeader]
+	var body []byte
+	if header[2]&pageTokenEncrypted != 0 {
+		aead, err := newPageTokenAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		rest := b[pageTokenHeader:]
+		if len(rest) < aead.NonceSize() {
+			return nil, ErrPageTokenInvalid
+		}
+		body, err = aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], header)
+		if err != nil {
+			return nil, ErrPageTokenInvalid
+		}
+	} else {
+		if c.Encrypt {
+			return nil, fmt.Errorf("%w: not encrypted", ErrPageTokenInvalid)
+		}
+		if len(b) < pageTokenHeader+pageTokenMACSize {
+			return nil, ErrPageTokenInvalid
+		}
+		signed, mac := b[:len(b)-pageTokenMACSize], b[len(b)-pageTokenMACSize:]
+		if !hmac.Equal(mac, pageTokenMAC(key, signed)) {
+			return nil, ErrPageTokenInvalid
+		}
+		body = signed[pageTokenHeader:]
+	}
+
+	rd := bytes.NewReader(body)
+	expiresAt, err := binary.ReadUvarint(rd)
+	if err != nil {
+		return nil, ErrPageTokenInvalid
+	}
+	offset, err := binary.ReadUvarint(rd)
+	if err != nil {
+		return nil, ErrPageTokenInvalid
+	}
+	fp := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(rd, fp); err != nil {
+		return nil, ErrPageTokenInvalid
+	}
+	cursor, _ := io.ReadAll(rd)
+
+	if expiresAt > 0 && c.now().Unix() > int64(expiresAt) {
+		return nil, ErrPageTokenExpired
+	}
+	return &PageToken{
+		Cursor:      base64.RawURLEncoding.EncodeToString(cursor),
+		Offset:      int(offset),
+		Fingerprint: hex.EncodeToString(fp),
+	}, nil
+}
+
+func pageTokenMAC(key *PageTokenKey, b []byte) []byte {
+	mac := hmac.New(sha256.New, key.derive("mac"))
+	mac.Write(b)
+	return mac.Sum(nil)[:pageTokenMACSize]
+}
+
+func newPageTokenAEAD(key *PageTokenKey) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key.derive("encryption"))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Next returns the token of the page which starts at cursor, or at offset
+// if cursor is empty.
+func (c *PageTokenCodec) Next(qb *QueryBuilder, cursor *datastore.Cursor, offset int) (string, error) {
+	fp, err := qb.PageFingerprint()
+	if err != nil {
+		return "", err
+	}
+	t := &PageToken{Offset: offset, Fingerprint: fp}
+	if cursor != nil {
+		t.Cursor = cursor.String()
+	}
+	return c.Encode(t)
+}
+
+// Apply verifies pageToken against qb and returns a clone of qb with the
+// cursor, the offset and the limit of the page. The first page is given by
+// an empty token.
+func (c *PageTokenCodec) Apply(qb *QueryBuilder, pageSize int, pageToken string) (*QueryBuilder, error) {
+	return c.apply(qb, qb, pageSize, pageToken)
+}
+
+// apply verifies pageToken against verified, which may have conditions
+// added by policies to qb.
+func (c *PageTokenCodec) apply(qb, verified *QueryBuilder, pageSize int, pageToken string) (*QueryBuilder, error) {
+	r := qb.Clone()
+	r.Cursor = ""
+	r.Filters = []*ValuedFilter{}
+	for _, f := range qb.Filters {
+		if f.Name != "offset" && f.Name != "limit" {
+			r.Filters = append(r.Filters, f)
+		}
+	}
+	if pageToken != "" {
+		t, err := c.Decode(pageToken)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.Verify(verified); err != nil {
+			return nil, err
+		}
+		if err := validateCursor(t.Cursor); err != nil {
+			return nil, err
+		}
+		r.Cursor = t.Cursor
+		if t.Offset > 0 {
+			r.Offset(t.Offset)
+		}
+	}
+	if pageSize > 0 {
+		r.Limit(pageSize)
+	}
+	r.signedPaging = true
+	return r, nil
+}
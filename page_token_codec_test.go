@@ -0,0 +1,136 @@
+package querybuilder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageTokenCodec(t *testing.T) {
+	cursor, err := datastore.DecodeCursor("Y3Vyc29y")
+	assert.NoError(t, err)
+	key1 := &PageTokenKey{ID: 1, Secret: []byte("secret1")}
+	key2 := &PageTokenKey{ID: 2, Secret: []byte("secret2")}
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	newBuilder := func() *QueryBuilder {
+		return New().Eq("Int2", 1).Desc("Int1")
+	}
+
+	for _, encrypt := range []bool{false, true} {
+		c := NewPageTokenCodec(key1)
+		c.Encrypt = encrypt
+		c.TTL = time.Hour
+		c.Now = func() time.Time { return now }
+
+		token, err := c.Next(newBuilder(), &cursor, 0)
+		assert.NoError(t, err)
+		assert.True(t, len(token) < 120, token)
+
+		{
+			b, err := c.Apply(newBuilder().Offset(3).Limit(100), 10, token)
+			assert.NoError(t, err)
+			assert.Equal(t, cursor.String(), b.Cursor)
+			assert.Equal(t, []*ValuedFilter{{Name: "limit", IntValue: 10}}, b.Filters)
+		}
+
+		{ // Offset
+			token, err := c.Next(newBuilder(), nil, 20)
+			assert.NoError(t, err)
+			b, err := c.Apply(newBuilder(), 10, token)
+			assert.NoError(t, err)
+			assert.Equal(t, "", b.Cursor)
+			offset, _ := b.IntFilterValue("offset")
+			assert.Equal(t, 20, offset)
+		}
+
+		{ // Another query
+			_, err := c.Apply(New().Eq("Int2", 2).Desc("Int1"), 10, token)
+			assert.Equal(t, ErrPageTokenMismatch, err)
+		}
+
+		{ // Tampered
+			b := []byte(token)
+			b[len(b)/2] ^= 1
+			_, err := c.Apply(newBuilder(), 10, string(b))
+			assert.True(t, errors.Is(err, ErrPageTokenInvalid), encrypt)
+		}
+
+		{ // Rotated keys
+			rotated := NewPageTokenCodec(key2, key1)
+			rotated.Encrypt = encrypt
+			rotated.Now = c.Now
+			_, err := rotated.Apply(newBuilder(), 10, token)
+			assert.NoError(t, err)
+
+			removed := NewPageTokenCodec(key2)
+			removed.Encrypt = encrypt
+			_, err = removed.Apply(newBuilder(), 10, token)
+			assert.True(t, errors.Is(err, ErrPageTokenInvalid))
+		}
+
+		{ // Expired
+			c.Now = func() time.Time { return now.Add(2 * time.Hour) }
+			_, err := c.Apply(newBuilder(), 10, token)
+			assert.Equal(t, ErrPageTokenExpired, err)
+		}
+	}
+
+	{ // Unencrypted tokens are rejected if encryption is required
+		token, err := NewPageTokenCodec(key1).Next(newBuilder(), &cursor, 0)
+		assert.NoError(t, err)
+		c := NewPageTokenCodec(key1)
+		c.Encrypt = true
+		_, err = c.Apply(newBuilder(), 10, token)
+		assert.True(t, errors.Is(err, ErrPageTokenInvalid))
+	}
+
+	for _, token := range []string{"!", "", "AQEA"} {
+		_, err := NewPageTokenCodec(key1).Decode(token)
+		assert.True(t, errors.Is(err, ErrPageTokenInvalid), token)
+	}
+}
+
+func TestExecutorPage(t *testing.T) {
+	ctx := context.Background()
+	cursor, err := datastore.DecodeCursor("Y3Vyc29y")
+	assert.NoError(t, err)
+
+	e := NewExecutor(&fakeClient{entities: Entities}, Kind4Test)
+	e.PageTokens = NewPageTokenCodec(&PageTokenKey{ID: 1, Secret: []byte("secret")})
+	e.Policies = []Policy{&RequiredEq{Field: "Str1", Value: "a"}}
+
+	token, err := e.NextPageToken(New().Desc("Int1"), &cursor, 0)
+	assert.NoError(t, err)
+
+	{
+		b, err := e.Page(New().Desc("Int1"), 2, token)
+		assert.NoError(t, err)
+		assert.Equal(t, cursor.String(), b.Cursor)
+		_, err = e.Count(ctx, b)
+		assert.NoError(t, err)
+	}
+
+	{ // Tokens for another tenant are rejected
+		other := NewExecutor(e.Client, Kind4Test)
+		other.PageTokens = e.PageTokens
+		other.Policies = []Policy{&RequiredEq{Field: "Str1", Value: "b"}}
+		_, err := other.Page(New().Desc("Int1"), 2, token)
+		assert.Equal(t, ErrPageTokenMismatch, err)
+	}
+
+	{ // Raw paging is rejected
+		_, err := e.Count(ctx, New().StartAt(cursor))
+		assert.Equal(t, ErrPageTokenUnsigned, err)
+		var entities []*Entity4Test
+		_, err = e.GetAll(ctx, New().Offset(10), &entities)
+		assert.Equal(t, ErrPageTokenUnsigned, err)
+		_, err = e.GetAll(ctx, New().Limit(10), &entities)
+		assert.NoError(t, err)
+	}
+}
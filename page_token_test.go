@@ -0,0 +1,76 @@
+package querybuilder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageToken(t *testing.T) {
+	cursor, err := datastore.DecodeCursor("Y3Vyc29y")
+	assert.NoError(t, err)
+
+	newBuilder := func() *QueryBuilder {
+		b, err := New().Eq("Int2", 1).OrderBy("Int1 desc")
+		assert.NoError(t, err)
+		return b
+	}
+
+	token, err := newBuilder().Limit(10).NextPageToken(cursor)
+	assert.NoError(t, err)
+
+	{
+		b, err := newBuilder().Page(10, token)
+		assert.NoError(t, err)
+		assert.Equal(t, cursor.String(), b.Cursor)
+		limit, _ := b.IntFilterValue("limit")
+		assert.Equal(t, 10, limit)
+		q, _ := b.Build(datastore.NewQuery(Kind4Test))
+		assert.Equal(t, datastore.NewQuery(Kind4Test).Filter("Int2=", 1).Order("-Int1").Limit(10).Start(cursor), q)
+	}
+
+	{ // First page
+		b, err := newBuilder().Page(10, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "", b.Cursor)
+	}
+
+	{ // Another query
+		_, err := New().Eq("Int2", 2).Desc("Int1").Page(10, token)
+		assert.Equal(t, ErrPageTokenMismatch, err)
+		_, err = New().Eq("Int2", 1).Asc("Int1").Page(10, token)
+		assert.Equal(t, ErrPageTokenMismatch, err)
+	}
+
+	{ // Broken tokens
+		for _, s := range []string{"!", "e30", token[1:]} {
+			_, err := newBuilder().Page(10, s)
+			assert.Error(t, err, s)
+		}
+	}
+
+	{ // Invalid cursors are rejected before running
+		b := New()
+		b.Cursor = "!"
+		_, err := NewExecutor(&fakeClient{}, Kind4Test).Count(context.Background(), b)
+		assert.Error(t, err)
+	}
+
+	{ // Invalid cursors are rejected when they are set
+		fp, err := newBuilder().PageFingerprint()
+		assert.NoError(t, err)
+		s, err := (&PageToken{Cursor: "!", Fingerprint: fp}).Encode()
+		assert.NoError(t, err)
+		_, err = newBuilder().Page(10, s)
+		assert.Error(t, err)
+
+		b := New()
+		assert.Error(t, json.Unmarshal([]byte(`{"cursor": "!"}`), b))
+		assert.NoError(t, json.Unmarshal([]byte(`{"cursor": "Y3Vyc29y"}`), b))
+		assert.Equal(t, "Y3Vyc29y", b.Cursor)
+	}
+}
@@ -0,0 +1,168 @@
+package querybuilder
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	StringParamType = "string"
+	IntParamType    = "int"
+	FloatParamType  = "float"
+	BoolParamType   = "bool"
+	TimeParamType   = TimeValueType
+)
+
+// Param is a placeholder of a value which is given by Bind.
+// It's serialized in JSON as {"param":"name"}.
+type Param struct {
+	Name string `json:"param"`
+	Type string `json:"type,omitempty"`
+}
+
+func Placeholder(name string) Param {
+	return Param{Name: name}
+}
+
+func TypedPlaceholder(name, typ string) Param {
+	return Param{Name: name, Type: typ}
+}
+
+func ParamFromMap(m map[string]interface{}) (Param, error) {
+	name, ok := m["param"].(string)
+	if !ok || name == "" {
+		return Param{}, fmt.Errorf("Invalid param: %v", m)
+	}
+	typ, _ := m["type"].(string)
+	return Param{Name: name, Type: typ}, nil
+}
+
+func (p Param) Convert(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	invalid := func() (interface{}, error) {
+		return nil, fmt.Errorf("Param %s requires %s but was %T", p.Name, p.Type, v)
+	}
+	switch p.Type {
+	case "":
+		return v, nil
+	case StringParamType:
+		if rv.Kind() != reflect.String {
+			return invalid()
+		}
+		return v, nil
+	case IntParamType:
+		switch {
+		case isIntKind(rv.Kind()):
+			return v, nil
+		case rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64:
+			if f := rv.Float(); f == math.Trunc(f) {
+				return int64(f), nil
+			}
+		}
+		return invalid()
+	case FloatParamType:
+		if !isNumberKind(rv.Kind()) {
+			return invalid()
+		}
+		return toFloat64(rv), nil
+	case BoolParamType:
+		if rv.Kind() != reflect.Bool {
+			return invalid()
+		}
+		return v, nil
+	case TimeParamType:
+		switch t := v.(type) {
+		case time.Time:
+			return t, nil
+		case string:
+			r, err := time.Parse(time.RFC3339Nano, t)
+			if err != nil {
+				return nil, fmt.Errorf("Param %s: %v", p.Name, err)
+			}
+			return r, nil
+		}
+		return invalid()
+	default:
+		return nil, fmt.Errorf("Unknown type of param %s: %s", p.Name, p.Type)
+	}
+}
+
+type BindError struct {
+	Missing Strings
+	Unused  Strings
+}
+
+func (e *BindError) Error() string {
+	msgs := []string{}
+	if len(e.Missing) > 0 {
+		msgs = append(msgs, "missing params: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Unused) > 0 {
+		msgs = append(msgs, "unused params: "+strings.Join(e.Unused, ", "))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (qb *QueryBuilder) Params() Strings {
+	r := Strings{}
+	for _, c := range qb.Conditions {
+		if p, ok := c.Value.(Param); ok {
+			r = append(r, p.Name)
+		}
+	}
+	for _, a := range qb.Assigns {
+		if p, ok := a.Value.(Param); ok {
+			r = append(r, p.Name)
+		}
+	}
+	return r.Uniq()
+}
+
+// Bind returns a copy of qb whose placeholders are replaced with values.
+func (qb *QueryBuilder) Bind(values map[string]interface{}) (*QueryBuilder, error) {
+	params := qb.Params()
+	bindErr := &BindError{Missing: Strings{}, Unused: Strings{}}
+	for _, name := range params {
+		if _, ok := values[name]; !ok {
+			bindErr.Missing = append(bindErr.Missing, name)
+		}
+	}
+	for name := range values {
+		if !params.Has(name) {
+			bindErr.Unused = append(bindErr.Unused, name)
+		}
+	}
+	if len(bindErr.Missing) > 0 || len(bindErr.Unused) > 0 {
+		sort.Strings(bindErr.Unused)
+		return nil, bindErr
+	}
+
+	bind := func(v interface{}) (interface{}, error) {
+		p, ok := v.(Param)
+		if !ok {
+			return v, nil
+		}
+		return p.Convert(values[p.Name])
+	}
+
+	r := qb.Clone()
+	for i, c := range r.Conditions {
+		v, err := bind(c.Value)
+		if err != nil {
+			return nil, err
+		}
+		r.Conditions[i] = &Condition{Field: c.Field, Ope: c.Ope, Value: v}
+	}
+	for i, a := range r.Assigns {
+		v, err := bind(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		r.Assigns[i] = &Assigner{Field: a.Field, Value: v}
+	}
+	return r, nil
+}
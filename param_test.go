@@ -0,0 +1,72 @@
+package querybuilder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParam(t *testing.T) {
+	template := New("Title", "Status", "Assignee").
+		Eq("Status", "open").
+		Eq("Assignee", Placeholder("user")).
+		Gt("CreatedAt", TypedPlaceholder("since", TimeParamType)).
+		Lt("Priority", TypedPlaceholder("priority", IntParamType))
+	assert.Equal(t, Strings{"user", "since", "priority"}, template.Params())
+
+	bytes, err := json.Marshal(template)
+	assert.NoError(t, err)
+	assert.Contains(t, string(bytes), `{"field":"Assignee","ope":"=","value":{"param":"user"}}`)
+	assert.Contains(t, string(bytes), `{"field":"CreatedAt","ope":"\u003e","value":{"param":"since","type":"time"}}`)
+
+	var stored QueryBuilder
+	assert.NoError(t, json.Unmarshal(bytes, &stored))
+	assert.Equal(t, template.Params(), stored.Params())
+	assert.Equal(t, Placeholder("user"), stored.Assigns[1].Value)
+
+	{
+		since := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+		b, err := stored.Bind(map[string]interface{}{
+			"user":     "alice",
+			"since":    "2019-12-01T00:00:00Z",
+			"priority": float64(3),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{
+			{"Status", EQ, "open"},
+			{"Assignee", EQ, "alice"},
+			{"CreatedAt", GT, since},
+			{"Priority", LT, int64(3)},
+		}, b.Conditions)
+		assert.Equal(t, Assigners{AssignerFor("Status", "open"), AssignerFor("Assignee", "alice")}, b.Assigns)
+		assert.Equal(t, Strings{}, b.Params())
+		// The template is not changed
+		assert.Equal(t, Strings{"user", "since", "priority"}, stored.Params())
+	}
+
+	{
+		_, err := template.Bind(map[string]interface{}{"user": "alice", "until": time.Now(), "foo": 1})
+		if assert.IsType(t, &BindError{}, err) {
+			bindErr := err.(*BindError)
+			assert.Equal(t, Strings{"since", "priority"}, bindErr.Missing)
+			assert.Equal(t, Strings{"foo", "until"}, bindErr.Unused)
+			assert.Equal(t, "missing params: since, priority; unused params: foo, until", err.Error())
+		}
+	}
+
+	{
+		_, err := template.Bind(map[string]interface{}{"user": "alice", "since": 1, "priority": 1})
+		assert.Error(t, err)
+		_, err = template.Bind(map[string]interface{}{"user": "alice", "since": time.Now(), "priority": 1.5})
+		assert.Error(t, err)
+	}
+
+	{
+		var entities []*Entity4Test
+		_, err := NewExecutor(&fakeClient{}, Kind4Test).GetAll(context.Background(), template, &entities)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,195 @@
+package querybuilder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"cloud.google.com/go/datastore"
+)
+
+// Policy validates or modifies a builder before it's built. Apply must be
+// idempotent because a builder may be given to policies more than once.
+type Policy interface {
+	Apply(qb *QueryBuilder) error
+}
+
+type PolicyFunc func(*QueryBuilder) error
+
+func (f PolicyFunc) Apply(qb *QueryBuilder) error {
+	return f(qb)
+}
+
+// ApplyPolicies returns a copy of qb which policies are applied to.
+func (qb *QueryBuilder) ApplyPolicies(policies ...Policy) (*QueryBuilder, error) {
+	r := qb.Clone()
+	for _, p := range policies {
+		if err := p.Apply(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (qb *QueryBuilder) BuildWithPolicies(q *datastore.Query, policies ...Policy) (*datastore.Query, Assigners, error) {
+	r, err := qb.ApplyPolicies(policies...)
+	if err != nil {
+		return nil, nil, err
+	}
+	q, assigns := r.Build(q)
+	return q, assigns, nil
+}
+
+// RequiredEq injects Field = Value into builders. Builders which have
+// other conditions on Field are rejected.
+type RequiredEq struct {
+	Field string
+	Value interface{}
+}
+
+func (p *RequiredEq) Apply(qb *QueryBuilder) error {
+	injected := false
+	for _, c := range qb.Conditions {
+		if c.Field != p.Field {
+			continue
+		}
+		if c.Ope != EQ || !reflect.DeepEqual(c.Value, p.Value) {
+			return fmt.Errorf("Condition on %s is not allowed", p.Field)
+		}
+		injected = true
+	}
+	for _, a := range qb.Assigns {
+		if a.Field == p.Field && !reflect.DeepEqual(a.Value, p.Value) {
+			return fmt.Errorf("Assign to %s is not allowed", p.Field)
+		}
+	}
+	if !injected {
+		qb.Eq(p.Field, p.Value)
+	}
+	return nil
+}
+
+// ForbiddenFields rejects builders which refer to any of the fields
+// in conditions, sort fields, projection, assigns or geo filter.
+type ForbiddenFields Strings
+
+func (p ForbiddenFields) Apply(qb *QueryBuilder) error {
+	fields := Strings(p)
+	check := func(where, field string) error {
+		if fields.Has(field) {
+			return fmt.Errorf("%s is not allowed in %s", field, where)
+		}
+		return nil
+	}
+	for _, c := range qb.Conditions {
+		if err := check("conditions", c.Field); err != nil {
+			return err
+		}
+	}
+	for _, f := range qb.ListSortFields() {
+		if err := check("sort_fields", strings.TrimPrefix(f, "-")); err != nil {
+			return err
+		}
+	}
+	for _, f := range qb.Fields {
+		if err := check("fields", f); err != nil {
+			return err
+		}
+	}
+	for _, a := range qb.Assigns {
+		if err := check("assigns", a.Field); err != nil {
+			return err
+		}
+	}
+	if qb.Geo != nil {
+		if err := check("geo", qb.Geo.Field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AllowedOperators rejects conditions whose operator is not listed for
+// the field. Fields which are not in the map accept any operator.
+type AllowedOperators map[string][]Ope
+
+func (p AllowedOperators) Apply(qb *QueryBuilder) error {
+	for _, c := range qb.Conditions {
+		opes, ok := p[c.Field]
+		if !ok {
+			continue
+		}
+		allowed := false
+		for _, ope := range opes {
+			if ope == c.Ope {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("Operator %s is not allowed for %s", c.Ope, c.Field)
+		}
+	}
+	return nil
+}
+
+// MaxLimit sets the limit if it's not given, not positive or greater than
+// MaxLimit. Datastore takes a negative limit as no limit.
+type MaxLimit int
+
+func (p MaxLimit) Apply(qb *QueryBuilder) error {
+	limit, ok := qb.IntFilterValue("limit")
+	if ok && limit >= 1 && limit <= int(p) {
+		return nil
+	}
+	filters := []*ValuedFilter{}
+	for _, f := range qb.Filters {
+		if f.Name != "limit" {
+			filters = append(filters, f)
+		}
+	}
+	qb.Filters = filters
+	qb.Limit(int(p))
+	return nil
+}
+
+// MaxOffset rejects builders whose offset is negative or greater than
+// MaxOffset because Datastore reads all the skipped entities.
+type MaxOffset int
+
+func (p MaxOffset) Apply(qb *QueryBuilder) error {
+	offset, ok := qb.IntFilterValue("offset")
+	if ok && (offset < 0 || offset > int(p)) {
+		return fmt.Errorf("Offset %d is not allowed", offset)
+	}
+	return nil
+}
+
+// MaxScanLimit sets MaxScan of builders which evaluate inequalities on
+// client if it's not given, not positive or greater than MaxScanLimit.
+type MaxScanLimit int
+
+func (p MaxScanLimit) Apply(qb *QueryBuilder) error {
+	if qb.ClientIneq && (qb.MaxScan < 1 || qb.MaxScan > int(p)) {
+		qb.MaxScan = int(p)
+	}
+	return nil
+}
+
+// MaxBatchSize sets BatchSize if it's not given, not positive or greater
+// than MaxBatchSize.
+type MaxBatchSize int
+
+func (p MaxBatchSize) Apply(qb *QueryBuilder) error {
+	if qb.BatchSize < 1 || qb.BatchSize > int(p) {
+		qb.BatchSize = int(p)
+	}
+	return nil
+}
+
+// NoClientIneq rejects builders which evaluate inequalities on client.
+var NoClientIneq = PolicyFunc(func(qb *QueryBuilder) error {
+	if qb.ClientIneq {
+		return fmt.Errorf("client_ineq is not allowed")
+	}
+	return nil
+})
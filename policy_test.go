@@ -0,0 +1,146 @@
+package querybuilder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicies(t *testing.T) {
+	policies := []Policy{
+		&RequiredEq{Field: "TenantID", Value: "t1"},
+		ForbiddenFields{"Secret"},
+		AllowedOperators{"Str1": {EQ}},
+		MaxLimit(100),
+	}
+
+	clientJSON := func(s string) *QueryBuilder {
+		var b QueryBuilder
+		assert.NoError(t, json.Unmarshal([]byte(s), &b))
+		return &b
+	}
+
+	{
+		b := clientJSON(`{"fields":["Int1","TenantID"],"conditions":[{"field":"Int1","ope":">","value":1}],"filters":[{"name":"limit","value":1000}]}`)
+		r, err := b.ApplyPolicies(policies...)
+		assert.NoError(t, err)
+		assert.Equal(t, Conditions{{"Int1", GT, float64(1)}, {"TenantID", EQ, "t1"}}, r.Conditions)
+		assert.Equal(t, Strings{"TenantID"}, r.Ignored)
+		assert.Equal(t, Assigners{AssignerFor("TenantID", "t1")}, r.Assigns)
+		assert.Equal(t, Strings{"Int1"}, r.ProjectFields())
+		limit, _ := r.IntFilterValue("limit")
+		assert.Equal(t, 100, limit)
+		assert.Equal(t, 1, len(r.Filters))
+
+		// The original builder is not changed
+		assert.Equal(t, 1, len(b.Conditions))
+
+		// Idempotent
+		r2, err := r.ApplyPolicies(policies...)
+		assert.NoError(t, err)
+		assert.Equal(t, r, r2)
+	}
+
+	{
+		b := New().Limit(10)
+		r, err := b.ApplyPolicies(MaxLimit(100))
+		assert.NoError(t, err)
+		assert.Equal(t, b.Filters, r.Filters)
+	}
+
+	{ // Negative limit means no limit
+		r, err := clientJSON(`{"filters":[{"name":"limit","value":-1}]}`).ApplyPolicies(MaxLimit(100))
+		assert.NoError(t, err)
+		assert.Equal(t, []*ValuedFilter{{Name: "limit", IntValue: 100}}, r.Filters)
+	}
+
+	{ // Scan limits
+		scanPolicies := []Policy{MaxOffset(1000), MaxScanLimit(500), MaxBatchSize(50)}
+		r, err := clientJSON(`{"client_ineq":true,"batch_size":1000,"filters":[{"name":"offset","value":10}]}`).ApplyPolicies(scanPolicies...)
+		assert.NoError(t, err)
+		assert.Equal(t, 500, r.MaxScan)
+		assert.Equal(t, 50, r.BatchSize)
+		r, err = clientJSON(`{"client_ineq":true,"max_scan":100,"batch_size":-1}`).ApplyPolicies(scanPolicies...)
+		assert.NoError(t, err)
+		assert.Equal(t, 100, r.MaxScan)
+		assert.Equal(t, 50, r.BatchSize)
+		for _, s := range []string{
+			`{"filters":[{"name":"offset","value":-1}]}`,
+			`{"filters":[{"name":"offset","value":1001}]}`,
+		} {
+			_, err := clientJSON(s).ApplyPolicies(scanPolicies...)
+			assert.Error(t, err, s)
+		}
+		_, err = clientJSON(`{"client_ineq":true}`).ApplyPolicies(NoClientIneq)
+		assert.Error(t, err)
+		_, err = New().ApplyPolicies(NoClientIneq)
+		assert.NoError(t, err)
+	}
+
+	invalids := []string{
+		`{"conditions":[{"field":"TenantID","ope":"=","value":"t2"}]}`,
+		`{"conditions":[{"field":"TenantID","ope":">","value":"t1"}]}`,
+		`{"assigns":[{"field":"TenantID","value":"t2"}]}`,
+		`{"conditions":[{"field":"Secret","ope":"=","value":"x"}]}`,
+		`{"sort_fields":["-Secret"]}`,
+		`{"fields":["Secret"]}`,
+		`{"conditions":[{"field":"Str1","ope":">=","value":"a"}]}`,
+	}
+	for _, s := range invalids {
+		_, err := clientJSON(s).ApplyPolicies(policies...)
+		assert.Error(t, err, s)
+	}
+
+	{ // Sort by key for tie breaking
+		b := clientJSON(`{"sort_fields":["Int1"],"key_tie_breaker":true}`)
+		_, err := b.ApplyPolicies(ForbiddenFields{KeyField})
+		assert.Error(t, err)
+		_, err = New().TieBreakByKey().ApplyPolicies(ForbiddenFields{KeyField})
+		assert.NoError(t, err)
+	}
+
+	{
+		_, _, err := New().Eq("Secret", "x").BuildWithPolicies(datastore.NewQuery(Kind4Test), policies...)
+		assert.Error(t, err)
+		q, assigns, err := New().BuildWithPolicies(datastore.NewQuery(Kind4Test), policies...)
+		assert.NoError(t, err)
+		assert.NotNil(t, q)
+		assert.Equal(t, Assigners{AssignerFor("TenantID", "t1")}, assigns)
+	}
+}
+
+func TestExecutorWithPolicies(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRUCache(10)
+	cli := &fakeClient{entities: Entities}
+
+	executorFor := func(tenant string) *CachingExecutor {
+		e := NewExecutor(cli, Kind4Test)
+		e.Policies = []Policy{&RequiredEq{Field: "Str1", Value: tenant}}
+		return NewCachingExecutor(e, cache, time.Minute)
+	}
+
+	b := New().Gte("Int1", 2)
+	for _, tenant := range []string{"t1", "t2", "t1"} {
+		var entities []*Entity4Test
+		_, err := executorFor(tenant).GetAll(ctx, b, &entities)
+		assert.NoError(t, err)
+	}
+	// Cached for each tenant
+	assert.Equal(t, 2, cli.getAllCalls)
+
+	{
+		var entities []*Entity4Test
+		_, err := executorFor("t1").GetAll(ctx, New().Eq("Str1", "t2"), &entities)
+		assert.Error(t, err)
+		_, err = executorFor("t1").Count(ctx, New().Eq("Str1", "t2"))
+		assert.Error(t, err)
+		_, err = executorFor("t1").GetAll(ctx, New().Offset(-1), &entities)
+		assert.Error(t, err)
+	}
+}
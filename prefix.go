@@ -0,0 +1,84 @@
+package querybuilder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// PrefixUpperBound returns the smallest string greater than every string
+// starting with prefix in UTF-8 byte order. It returns false when there is no
+// such string, e.g. for an empty prefix.
+func PrefixUpperBound(prefix string) (string, bool) {
+	if !utf8.ValidString(prefix) {
+		r, ok := BytesPrefixUpperBound([]byte(prefix))
+		return string(r), ok
+	}
+	runes := []rune(prefix)
+	for i := len(runes) - 1; i >= 0; i-- {
+		r := runes[i] + 1
+		if r >= 0xD800 && r <= 0xDFFF { // surrogates are not valid in UTF-8
+			r = 0xE000
+		}
+		if r <= utf8.MaxRune {
+			runes[i] = r
+			return string(runes[:i+1]), true
+		}
+	}
+	return "", false
+}
+
+func BytesPrefixUpperBound(prefix []byte) ([]byte, bool) {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xFF {
+			r := make([]byte, i+1)
+			copy(r, prefix)
+			r[i]++
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+var FoldedFieldSuffix = "Folded"
+
+func FoldedField(field string) string {
+	return field + FoldedFieldSuffix
+}
+
+func Fold(s string) string {
+	return strings.ToLower(s)
+}
+
+func AssignFolded(entity interface{}, fields ...string) error {
+	e := reflect.ValueOf(entity)
+	if e.Type().Kind() == reflect.Ptr {
+		e = e.Elem()
+	}
+	for _, field := range fields {
+		values := []string{}
+		err := ReflectWalkIn(&e, field, ".", func(f *reflect.Value) error {
+			if f.Kind() != reflect.String {
+				return fmt.Errorf("%s is not a string but %v", field, f.Type())
+			}
+			values = append(values, Fold(f.String()))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		err = ReflectWalkIn(&e, FoldedField(field), ".", func(f *reflect.Value) error {
+			if f.Kind() != reflect.String {
+				return fmt.Errorf("%s is not a string but %v", FoldedField(field), f.Type())
+			}
+			f.SetString(values[0])
+			values = values[1:]
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
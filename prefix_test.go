@@ -0,0 +1,87 @@
+package querybuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixUpperBound(t *testing.T) {
+	patterns := map[string]string{
+		"ba":            "bb",
+		"a":             "b",
+		"a\u007f":       "a\u0080",
+		"a\uffff":       "a\U00010000",
+		"a\U0010ffff":   "b",
+		"\ud7ff":        "\ue000",
+		"ba\U0001f600x": "ba\U0001f600y",
+	}
+	for prefix, expected := range patterns {
+		r, ok := PrefixUpperBound(prefix)
+		assert.True(t, ok)
+		assert.Equal(t, expected, r, "%q", prefix)
+	}
+
+	for _, prefix := range []string{"", "\U0010ffff\U0010ffff"} {
+		_, ok := PrefixUpperBound(prefix)
+		assert.False(t, ok)
+	}
+
+	{
+		upper, _ := PrefixUpperBound("ba")
+		for _, s := range []string{"ba", "bar", "ba\ufffd", "ba\U0001f600", "ba\U0010ffff"} {
+			assert.True(t, s < upper, "%q", s)
+		}
+		assert.False(t, "bb" < upper)
+	}
+}
+
+func TestBytesPrefixUpperBound(t *testing.T) {
+	r, ok := BytesPrefixUpperBound([]byte{0x01, 0xff})
+	assert.True(t, ok)
+	assert.Equal(t, []byte{0x02}, r)
+
+	_, ok = BytesPrefixUpperBound([]byte{0xff, 0xff})
+	assert.False(t, ok)
+	_, ok = BytesPrefixUpperBound([]byte{})
+	assert.False(t, ok)
+}
+
+func TestStarts(t *testing.T) {
+	{
+		b := New().Starts("Str2", "ba")
+		assert.Equal(t, Conditions{{"Str2", GTE, "ba"}, {"Str2", LT, "bb"}}, b.Conditions)
+		assert.Equal(t, Strings{"Str2"}, b.SortFields)
+	}
+	{
+		b := New().Starts("Str2", "")
+		assert.Equal(t, Conditions{{"Str2", GTE, ""}}, b.Conditions)
+	}
+	{
+		b := New().StartsBytes("Data", []byte{0x10})
+		assert.Equal(t, Conditions{{"Data", GTE, []byte{0x10}}, {"Data", LT, []byte{0x11}}}, b.Conditions)
+	}
+	{
+		b := New().StartsFold("Name", "Ba")
+		assert.Equal(t, Conditions{{"NameFolded", GTE, "ba"}, {"NameFolded", LT, "bb"}}, b.Conditions)
+	}
+}
+
+func TestAssignFolded(t *testing.T) {
+	type sub struct {
+		Name       string
+		NameFolded string
+	}
+	type entity struct {
+		Name       string
+		NameFolded string
+		Subs       []sub
+	}
+	e := &entity{Name: "FooBAR", Subs: []sub{{Name: "Ä"}, {Name: "bAz"}}}
+	assert.NoError(t, AssignFolded(e, "Name", "Subs.Name"))
+	assert.Equal(t, "foobar", e.NameFolded)
+	assert.Equal(t, "ä", e.Subs[0].NameFolded)
+	assert.Equal(t, "baz", e.Subs[1].NameFolded)
+
+	assert.Error(t, AssignFolded(e, "Unknown"))
+}
@@ -0,0 +1,246 @@
+package querybuilder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"cloud.google.com/go/datastore"
+)
+
+// MergeRowsByKey merges the rows which projection queries return for each
+// value of multi-valued properties into an entity per key. Repeated values
+// of a property are merged into one because Datastore returns a row for
+// each distinct value.
+func MergeRowsByKey(keys []*datastore.Key, dst interface{}) ([]*datastore.Key, error) {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Unsupported type of destination %T", dst)
+	}
+	dv = dv.Elem()
+	if dv.Len() != len(keys) {
+		return nil, fmt.Errorf("%d keys for %d entities", len(keys), dv.Len())
+	}
+
+	r := []*datastore.Key{}
+	rows := reflect.MakeSlice(dv.Type(), 0, dv.Len())
+	indexes := map[string]int{}
+	for i, key := range keys {
+		row := dv.Index(i)
+		if idx, ok := indexes[key.String()]; ok {
+			mergeValue(rows.Index(idx), row)
+			continue
+		}
+		indexes[key.String()] = len(r)
+		r = append(r, key)
+		rows = reflect.Append(rows, row)
+	}
+	dv.Set(rows)
+	return r, nil
+}
+
+func mergeValue(dst, src reflect.Value) {
+	switch dst.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if dst.IsNil() {
+			dst.Set(src)
+		} else if !src.IsNil() {
+			mergeValue(dst.Elem(), src.Elem())
+		}
+	case reflect.Struct:
+		if dst.IsZero() {
+			dst.Set(src)
+			return
+		}
+		for i := 0; i < dst.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				mergeValue(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			if dst.Len() == 0 {
+				dst.Set(src)
+			}
+			return
+		}
+		for i := 0; i < src.Len(); i++ {
+			if !containsValue(dst, src.Index(i)) {
+				dst.Set(reflect.Append(dst, src.Index(i)))
+			}
+		}
+	default:
+		if dst.CanSet() && dst.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
+
+func containsValue(s, v reflect.Value) bool {
+	for i := 0; i < s.Len(); i++ {
+		if reflect.DeepEqual(s.Index(i).Interface(), v.Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// DoMatched works like Do but it sets the value into an element of
+// multi-valued properties only when no element has the value yet and
+// the element is the only one which can match the condition.
+func (a *Assigner) DoMatched(entity interface{}) error {
+	e := reflect.ValueOf(entity)
+	if e.Type().Kind() == reflect.Ptr {
+		e = e.Elem()
+	}
+	if e.Type().Kind() != reflect.Struct {
+		return fmt.Errorf("Entity type: %T is not a struct. %v", entity, entity)
+	}
+	return assignMatched(e, strings.Split(a.Field, "."), reflect.ValueOf(a.Value))
+}
+
+func assignMatched(curr reflect.Value, fields []string, v reflect.Value) error {
+	switch curr.Kind() {
+	case reflect.Slice, reflect.Array:
+		if curr.Type().Elem().Kind() != reflect.Uint8 || len(fields) > 0 {
+			if hasValue(curr, fields, v) {
+				return nil
+			}
+			switch {
+			case curr.Len() == 1:
+				return assignMatched(curr.Index(0), fields, v)
+			case curr.Len() == 0 && len(fields) == 0 && curr.Kind() == reflect.Slice:
+				e := reflect.New(curr.Type().Elem()).Elem()
+				e.Set(convertValue(v, e.Type()))
+				curr.Set(reflect.Append(curr, e))
+			}
+			return nil
+		}
+	}
+	if len(fields) < 1 {
+		curr.Set(convertValue(v, curr.Type()))
+		return nil
+	}
+	if curr.Kind() != reflect.Struct {
+		return fmt.Errorf("%s is not struct but %v", curr.String(), curr.Interface())
+	}
+	field := curr.FieldByName(fields[0])
+	if !field.IsValid() {
+		return fmt.Errorf("%s has no field named %s", curr.String(), fields[0])
+	}
+	return assignMatched(field, fields[1:], v)
+}
+
+func hasValue(curr reflect.Value, fields []string, v reflect.Value) bool {
+	found := false
+	ReflectWalkInImpl(&curr, fields, func(f *reflect.Value) error {
+		values := []reflect.Value{*f}
+		if f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8 {
+			values = values[:0]
+			for i := 0; i < f.Len(); i++ {
+				values = append(values, f.Index(i))
+			}
+		}
+		for _, i := range values {
+			if r, err := CompareValues(i.Interface(), v.Interface()); err == nil && r == 0 {
+				found = true
+			}
+		}
+		return nil
+	})
+	return found
+}
+
+func (s Assigners) AssignMatched(entity interface{}) error {
+	for _, i := range s {
+		if err := i.DoMatched(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s Assigners) AssignAllMatched(entities interface{}) error {
+	v := reflect.ValueOf(entities)
+	switch v.Type().Kind() {
+	case reflect.Slice:
+		l := v.Len()
+		for i := 0; i < l; i++ {
+			e := v.Index(i)
+			if e.Kind() != reflect.Ptr {
+				e = e.Addr()
+			}
+			if err := s.AssignMatched(e.Interface()); err != nil {
+				return err
+			}
+		}
+	case reflect.Ptr:
+		return s.AssignAllMatched(v.Elem().Interface())
+	default:
+		return fmt.Errorf("Unsupported type of slice %T", entities)
+	}
+	return nil
+}
+
+// PostProcess merges projection rows by key and applies Assigns to them.
+func (qb *QueryBuilder) PostProcess(keys []*datastore.Key, dst interface{}) ([]*datastore.Key, error) {
+	return qb.postProcess(keys, dst, qb.Assigns)
+}
+
+// postProcess works like PostProcess with assigns returned by Build,
+// which interceptors may have changed.
+func (qb *QueryBuilder) postProcess(keys []*datastore.Key, dst interface{}, assigns Assigners) ([]*datastore.Key, error) {
+	if fields := qb.ProjectFields(); len(fields) > 0 {
+		if err := checkProjection(reflect.TypeOf(dst), fields); err != nil {
+			return nil, err
+		}
+		var err error
+		keys, err = MergeRowsByKey(keys, dst)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := assigns.AssignAllMatched(dst); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// checkProjection rejects fields which have more than one sub-property of
+// the same slice of structs. Datastore returns a row for each combination
+// of their values, so the elements can't be merged from the rows.
+func checkProjection(t reflect.Type, fields Strings) error {
+	t = valueType(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	slices := map[string]string{}
+	for _, field := range fields {
+		names := strings.Split(field, ".")
+		curr := t
+		for i, name := range names[:len(names)-1] {
+			curr = valueType(curr)
+			if curr.Kind() != reflect.Struct {
+				break
+			}
+			sf, ok := findStructField(curr, name)
+			if !ok {
+				break
+			}
+			st := sf.Type
+			for st.Kind() == reflect.Ptr {
+				st = st.Elem()
+			}
+			if st.Kind() == reflect.Slice {
+				prop := strings.Join(names[:i+1], ".")
+				if other, ok := slices[prop]; ok && other != field {
+					return fmt.Errorf("Can't project %s and %s of the same slice %s", other, field, prop)
+				}
+				slices[prop] = field
+				break
+			}
+			curr = sf.Type
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package querybuilder
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeRowsByKey(t *testing.T) {
+	key5 := datastore.IDKey(ComplicatedKind4Test, 5, nil)
+	key6 := datastore.IDKey(ComplicatedKind4Test, 6, nil)
+	keys := []*datastore.Key{key5, key5, key6, key6}
+	rows := []*ComplicatedEntity4Test{
+		{ID: 5, Name: "Quux", Strings: []string{"d"}, Subs: []SubEntity{{S1: "A"}}},
+		{ID: 5, Name: "Quux", Strings: []string{"d"}, Subs: []SubEntity{{S1: "C"}}},
+		{ID: 6, Name: "Corge", Strings: []string{"b"}, Subs: []SubEntity{{S1: "B"}}},
+		{ID: 6, Name: "Corge", Strings: []string{"b"}, Subs: []SubEntity{{S1: "C"}}},
+	}
+
+	r, err := MergeRowsByKey(keys, &rows)
+	assert.NoError(t, err)
+	assert.Equal(t, []*datastore.Key{key5, key6}, r)
+	assert.Equal(t, []*ComplicatedEntity4Test{
+		{ID: 5, Name: "Quux", Strings: []string{"d"}, Subs: []SubEntity{{S1: "A"}, {S1: "C"}}},
+		{ID: 6, Name: "Corge", Strings: []string{"b"}, Subs: []SubEntity{{S1: "B"}, {S1: "C"}}},
+	}, rows)
+
+	_, err = MergeRowsByKey(keys[:1], &rows)
+	assert.Error(t, err)
+}
+
+func TestAssignMatched(t *testing.T) {
+	entities := []*ComplicatedEntity4Test{
+		{ID: 4, Subs: []SubEntity{{S1: "A"}, {S1: "C"}}},               // ambiguous projection rows
+		{ID: 5, Subs: []SubEntity{{S1: "C"}}},                          // the only element must match
+		{ID: 6, Subs: []SubEntity{{I1: 1, S1: "A"}, {I1: 3, S1: "C"}}}, // already has the value
+		{ID: 7},
+	}
+	assert.NoError(t, Assigners{AssignerFor("Subs.I1", 3), AssignerFor("Sub1.I1", 2), AssignerFor("Strings", "x")}.AssignAllMatched(&entities))
+
+	assert.Equal(t, []SubEntity{{S1: "A"}, {S1: "C"}}, entities[0].Subs)
+	assert.Equal(t, []SubEntity{{I1: 3, S1: "C"}}, entities[1].Subs)
+	assert.Equal(t, []SubEntity{{I1: 1, S1: "A"}, {I1: 3, S1: "C"}}, entities[2].Subs)
+	assert.Equal(t, 0, len(entities[3].Subs))
+	for _, e := range entities {
+		assert.Equal(t, 2, e.Sub1.I1)
+		assert.Equal(t, []string{"x"}, e.Strings)
+	}
+
+	assert.Error(t, AssignerFor("Unknown", 1).DoMatched(entities[0]))
+}
+
+func TestExecutorWithProjection(t *testing.T) {
+	rows := []*ComplicatedEntity4Test{
+		{ID: 5, Name: "Quux", Subs: []SubEntity{{S1: "A"}}},
+		{ID: 5, Name: "Quux", Subs: []SubEntity{{S1: "C"}}},
+		{ID: 7, Name: "Grault", Subs: []SubEntity{{S1: "C"}}},
+	}
+	cli := &fakeClient{entities: rows, keyIDs: []int64{5, 5, 7}}
+	b := New("ID", "Name", "Subs.I1", "Subs.S1").Eq("Subs.I1", 3)
+
+	var entities []*ComplicatedEntity4Test
+	keys, err := NewExecutor(cli, ComplicatedKind4Test).GetAll(context.Background(), b, &entities)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(keys))
+	assert.Equal(t, []*ComplicatedEntity4Test{
+		{ID: 5, Name: "Quux", Subs: []SubEntity{{S1: "A"}, {S1: "C"}}},
+		{ID: 7, Name: "Grault", Subs: []SubEntity{{I1: 3, S1: "C"}}},
+	}, entities)
+
+	{ // Sub-properties of a slice of structs can't be merged
+		var entities []*ComplicatedEntity4Test
+		_, err := NewExecutor(cli, ComplicatedKind4Test).GetAll(context.Background(), New("ID", "Subs.I1", "Subs.S1"), &entities)
+		assert.Error(t, err)
+	}
+}
+
+func TestCheckProjection(t *testing.T) {
+	typ := reflect.TypeOf([]*ComplicatedEntity4Test{})
+	assert.NoError(t, checkProjection(typ, Strings{"ID", "Strings", "Ints", "Subs.S1"}))
+	assert.NoError(t, checkProjection(typ, Strings{"Sub1.I1", "Sub1.S1", "Subs.I1"}))
+	assert.Error(t, checkProjection(typ, Strings{"Subs.I1", "Subs.S1"}))
+	assert.NoError(t, checkProjection(reflect.TypeOf([]datastore.PropertyList{}), Strings{"Subs.I1", "Subs.S1"}))
+}
@@ -0,0 +1,214 @@
+package querybuilder
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/akm/querybuilder/querybuilderpb"
+)
+
+var opeToProto = map[Ope]pb.Operator{
+	LT:  pb.Operator_LESS_THAN,
+	LTE: pb.Operator_LESS_THAN_OR_EQUAL,
+	GT:  pb.Operator_GREATER_THAN,
+	GTE: pb.Operator_GREATER_THAN_OR_EQUAL,
+	EQ:  pb.Operator_EQUAL,
+}
+
+var opeFromProto = func() map[pb.Operator]Ope {
+	r := map[pb.Operator]Ope{}
+	for k, v := range opeToProto {
+		r[v] = k
+	}
+	return r
+}()
+
+// ToProto converts qb to the message of querybuilder.proto.
+// Integers and floats are converted to int64 and float64 like Datastore
+// stores them, so FromProto returns them in int64 and float64.
+func ToProto(qb *QueryBuilder) (*pb.QueryBuilder, error) {
+	r := &pb.QueryBuilder{
+		Fields:        qb.Fields,
+		Ignored:       qb.Ignored,
+		SortFields:    qb.SortFields,
+		ClientIneq:    qb.ClientIneq,
+		MaxScan:       int64(qb.MaxScan),
+		BatchSize:     int64(qb.BatchSize),
+		Cursor:        qb.Cursor,
+		KeyTieBreaker: qb.KeyTieBreaker,
+	}
+	for _, c := range qb.Conditions {
+		ope, ok := opeToProto[c.Ope]
+		if !ok {
+			return nil, fmt.Errorf("Unknown operator %q of %s", c.Ope, c.Field)
+		}
+		v, err := ValueToProto(c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value of %s: %v", c.Field, err)
+		}
+		r.Conditions = append(r.Conditions, &pb.Condition{Field: c.Field, Ope: ope, Value: v})
+	}
+	for _, f := range qb.Filters {
+		r.Filters = append(r.Filters, &pb.ValuedFilter{Name: f.Name, Value: int64(f.IntValue)})
+	}
+	for _, a := range qb.Assigns {
+		v, err := ValueToProto(a.Value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value of %s: %v", a.Field, err)
+		}
+		r.Assigns = append(r.Assigns, &pb.Assigner{Field: a.Field, Value: v})
+	}
+	if g := qb.Geo; g != nil {
+		r.Geo = &pb.GeoFilter{Field: g.Field, Lat: g.Lat, Lng: g.Lng, Radius: g.Radius, SortByDistance: g.SortByDistance}
+	}
+	return r, nil
+}
+
+func FromProto(m *pb.QueryBuilder) (*QueryBuilder, error) {
+	if err := validateCursor(m.Cursor); err != nil {
+		return nil, err
+	}
+	r := &QueryBuilder{
+		Fields:        m.Fields,
+		Ignored:       m.Ignored,
+		SortFields:    m.SortFields,
+		ClientIneq:    m.ClientIneq,
+		MaxScan:       int(m.MaxScan),
+		BatchSize:     int(m.BatchSize),
+		Cursor:        m.Cursor,
+		KeyTieBreaker: m.KeyTieBreaker,
+	}
+	for _, c := range m.Conditions {
+		ope, ok := opeFromProto[c.Ope]
+		if !ok {
+			return nil, fmt.Errorf("Unknown operator %v of %s", c.Ope, c.Field)
+		}
+		v, err := ValueFromProto(c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value of %s: %v", c.Field, err)
+		}
+		r.AddCondition(c.Field, ope, v)
+	}
+	for _, f := range m.Filters {
+		r.AddIntFilter(f.Name, int(f.Value))
+	}
+	for _, a := range m.Assigns {
+		v, err := ValueFromProto(a.Value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value of %s: %v", a.Field, err)
+		}
+		r.Assigns = append(r.Assigns, &Assigner{Field: a.Field, Value: v})
+	}
+	if g := m.Geo; g != nil {
+		r.Geo = &GeoFilter{Field: g.Field, Lat: g.Lat, Lng: g.Lng, Radius: g.Radius, SortByDistance: g.SortByDistance}
+	}
+	return r, nil
+}
+
+func ValueToProto(v interface{}) (*pb.Value, error) {
+	switch x := v.(type) {
+	case nil:
+		return nullValue(), nil
+	case Param:
+		return &pb.Value{Kind: &pb.Value_ParamValue{ParamValue: &pb.Param{Name: x.Name, Type: x.Type}}}, nil
+	case time.Time:
+		return &pb.Value{Kind: &pb.Value_TimestampValue{TimestampValue: timestamppb.New(x)}}, nil
+	case *datastore.Key:
+		if x == nil {
+			return nullValue(), nil
+		}
+		return &pb.Value{Kind: &pb.Value_KeyValue{KeyValue: keyToProto(x)}}, nil
+	case datastore.GeoPoint:
+		return &pb.Value{Kind: &pb.Value_GeoPointValue{GeoPointValue: &pb.GeoPoint{Lat: x.Lat, Lng: x.Lng}}}, nil
+	case []byte:
+		return &pb.Value{Kind: &pb.Value_BytesValue{BytesValue: x}}, nil
+	}
+	rv := reflect.ValueOf(v)
+	switch k := rv.Kind(); {
+	case isIntKind(k):
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: toInt64(rv)}}, nil
+	case isNumberKind(k):
+		return &pb.Value{Kind: &pb.Value_DoubleValue{DoubleValue: rv.Float()}}, nil
+	case k == reflect.String:
+		return &pb.Value{Kind: &pb.Value_StringValue{StringValue: rv.String()}}, nil
+	case k == reflect.Bool:
+		return &pb.Value{Kind: &pb.Value_BoolValue{BoolValue: rv.Bool()}}, nil
+	case k == reflect.Slice || k == reflect.Array:
+		values := []*pb.Value{}
+		for i := 0; i < rv.Len(); i++ {
+			e, err := ValueToProto(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, e)
+		}
+		return &pb.Value{Kind: &pb.Value_ArrayValue{ArrayValue: &pb.ArrayValue{Values: values}}}, nil
+	default:
+		return nil, fmt.Errorf("Unsupported value %v (%T)", v, v)
+	}
+}
+
+func nullValue() *pb.Value {
+	return &pb.Value{Kind: &pb.Value_NullValue{NullValue: structpb.NullValue_NULL_VALUE}}
+}
+
+func ValueFromProto(m *pb.Value) (interface{}, error) {
+	switch x := m.GetKind().(type) {
+	case nil:
+		return nil, fmt.Errorf("No value")
+	case *pb.Value_NullValue:
+		return nil, nil
+	case *pb.Value_IntValue:
+		return x.IntValue, nil
+	case *pb.Value_DoubleValue:
+		return x.DoubleValue, nil
+	case *pb.Value_StringValue:
+		return x.StringValue, nil
+	case *pb.Value_BoolValue:
+		return x.BoolValue, nil
+	case *pb.Value_TimestampValue:
+		if err := x.TimestampValue.CheckValid(); err != nil {
+			return nil, err
+		}
+		return x.TimestampValue.AsTime(), nil
+	case *pb.Value_KeyValue:
+		return keyFromProto(x.KeyValue), nil
+	case *pb.Value_BytesValue:
+		return x.BytesValue, nil
+	case *pb.Value_GeoPointValue:
+		return datastore.GeoPoint{Lat: x.GeoPointValue.Lat, Lng: x.GeoPointValue.Lng}, nil
+	case *pb.Value_ParamValue:
+		return Param{Name: x.ParamValue.Name, Type: x.ParamValue.Type}, nil
+	case *pb.Value_ArrayValue:
+		r := []interface{}{}
+		for _, e := range x.ArrayValue.Values {
+			v, err := ValueFromProto(e)
+			if err != nil {
+				return nil, err
+			}
+			r = append(r, v)
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("Unsupported value %v", m)
+	}
+}
+
+func keyToProto(k *datastore.Key) *pb.Key {
+	if k == nil {
+		return nil
+	}
+	return &pb.Key{Kind: k.Kind, Id: k.ID, Name: k.Name, Namespace: k.Namespace, Parent: keyToProto(k.Parent)}
+}
+
+func keyFromProto(m *pb.Key) *datastore.Key {
+	if m == nil {
+		return nil
+	}
+	return &datastore.Key{Kind: m.Kind, ID: m.Id, Name: m.Name, Namespace: m.Namespace, Parent: keyFromProto(m.Parent)}
+}
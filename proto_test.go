@@ -0,0 +1,91 @@
+package querybuilder
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/akm/querybuilder/querybuilderpb"
+)
+
+func TestProto(t *testing.T) {
+	roundTrip := func(b *QueryBuilder) *QueryBuilder {
+		m, err := ToProto(b)
+		assert.NoError(t, err)
+		data, err := proto.Marshal(m)
+		assert.NoError(t, err)
+		decoded := &pb.QueryBuilder{}
+		assert.NoError(t, proto.Unmarshal(data, decoded))
+		r, err := FromProto(decoded)
+		assert.NoError(t, err)
+		return r
+	}
+
+	{
+		at := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+		parent := datastore.NameKey("Parent", "p1", nil)
+		parent.Namespace = "ns"
+		b := New("Int1", "Str1").
+			Eq("Int2", int64(1)).
+			Eq("Str1", "a").
+			Eq("Flag", true).
+			Eq("Float", 1.5).
+			Eq("At", at).
+			Eq("Parent", datastore.IDKey("Entity", 2, parent)).
+			Eq("Bytes", []byte("abc")).
+			Eq("Nil", nil).
+			Eq("Location", datastore.GeoPoint{Lat: 1, Lng: 2}).
+			Eq("Tags", []interface{}{"a", int64(1)}).
+			Eq("P", TypedPlaceholder("p", IntParamType)).
+			Gte("Int1", int64(2)).
+			Desc("Str2").Offset(1).Limit(2)
+		b.ClientIneq = true
+		b.MaxScan = 100
+		b.BatchSize = 10
+		b.Cursor = "Y3Vyc29y"
+		b.KeyTieBreaker = true
+		b.Geo = &GeoFilter{Field: "Location", Lat: 1, Lng: 2, Radius: 3, SortByDistance: true}
+
+		assert.Equal(t, b, roundTrip(b))
+	}
+
+	{ // Null values are built into the query
+		r := roundTrip(New().Eq("Nil", nil).Gt("Int1", nil))
+		assert.Equal(t, Conditions{{"Nil", EQ, nil}, {"Int1", GT, nil}}, r.Conditions)
+		assert.Equal(t, Assigners{AssignerFor("Nil", nil)}, r.Assigns)
+		q, _ := r.Build(datastore.NewQuery(Kind4Test))
+		assert.Equal(t, datastore.NewQuery(Kind4Test).Order("Int1").Filter("Nil =", nil).Filter("Int1 >", nil), q)
+	}
+
+	{ // Numbers are normalized to int64 and float64
+		r := roundTrip(New().Eq("Int1", 1).Eq("EnumA", EnumA1).Eq("Float", float32(0.5)).Eq("Ints", []int{1, 2}))
+		assert.Equal(t, Conditions{
+			{"Int1", EQ, int64(1)},
+			{"EnumA", EQ, int64(1)},
+			{"Float", EQ, 0.5},
+			{"Ints", EQ, []interface{}{int64(1), int64(2)}},
+		}, r.Conditions)
+	}
+
+	{ // Builders round trip through JSON and proto in the same way
+		b := New("Int2", "Str1", "Str2").Eq("Int2", int64(1))
+		assert.Equal(t, b, roundTrip(b))
+	}
+
+	{
+		_, err := ToProto(New().AddCondition("Int1", Ope("!="), 1))
+		assert.Error(t, err)
+		_, err = ToProto(New().Eq("Int1", struct{}{}))
+		assert.Error(t, err)
+		_, err = FromProto(&pb.QueryBuilder{Conditions: []*pb.Condition{{Field: "Int1", Value: nullValue()}}})
+		assert.Error(t, err)
+		_, err = FromProto(&pb.QueryBuilder{Conditions: []*pb.Condition{{Field: "Int1", Ope: pb.Operator_EQUAL}}})
+		assert.Error(t, err)
+		_, err = FromProto(&pb.QueryBuilder{Cursor: "!"})
+		assert.Error(t, err)
+	}
+}
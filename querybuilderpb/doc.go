@@ -0,0 +1,6 @@
+// Package querybuilderpb has the Go code generated from
+// proto/querybuilder/v1/querybuilder.proto. Use querybuilder.ToProto and
+// querybuilder.FromProto to convert QueryBuilder.
+package querybuilderpb
+
+//go:generate protoc -I ../proto --go_out=. --go_opt=module=github.com/akm/querybuilder/querybuilderpb querybuilder/v1/querybuilder.proto
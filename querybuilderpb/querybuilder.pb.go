@@ -0,0 +1,1079 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: querybuilder/v1/querybuilder.proto
+
+package querybuilderpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Operator int32
+
+const (
+	Operator_OPERATOR_UNSPECIFIED  Operator = 0
+	Operator_LESS_THAN             Operator = 1
+	Operator_LESS_THAN_OR_EQUAL    Operator = 2
+	Operator_GREATER_THAN          Operator = 3
+	Operator_GREATER_THAN_OR_EQUAL Operator = 4
+	Operator_EQUAL                 Operator = 5
+)
+
+// Enum value maps for Operator.
+var (
+	Operator_name = map[int32]string{
+		0: "OPERATOR_UNSPECIFIED",
+		1: "LESS_THAN",
+		2: "LESS_THAN_OR_EQUAL",
+		3: "GREATER_THAN",
+		4: "GREATER_THAN_OR_EQUAL",
+		5: "EQUAL",
+	}
+	Operator_value = map[string]int32{
+		"OPERATOR_UNSPECIFIED":  0,
+		"LESS_THAN":             1,
+		"LESS_THAN_OR_EQUAL":    2,
+		"GREATER_THAN":          3,
+		"GREATER_THAN_OR_EQUAL": 4,
+		"EQUAL":                 5,
+	}
+)
+
+func (x Operator) Enum() *Operator {
+	p := new(Operator)
+	*p = x
+	return p
+}
+
+func (x Operator) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Operator) Descriptor() protoreflect.EnumDescriptor {
+	return file_querybuilder_v1_querybuilder_proto_enumTypes[0].Descriptor()
+}
+
+func (Operator) Type() protoreflect.EnumType {
+	return &file_querybuilder_v1_querybuilder_proto_enumTypes[0]
+}
+
+func (x Operator) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Operator.Descriptor instead.
+func (Operator) EnumDescriptor() ([]byte, []int) {
+	return file_querybuilder_v1_querybuilder_proto_rawDescGZIP(), []int{0}
+}
+
+// QueryBuilder mirrors querybuilder.QueryBuilder.
+type QueryBuilder struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fields        []string               `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	Ignored       []string               `protobuf:"bytes,2,rep,name=ignored,proto3" json:"ignored,omitempty"`
+	SortFields    []string               `protobuf:"bytes,3,rep,name=sort_fields,json=sortFields,proto3" json:"sort_fields,omitempty"`
+	Conditions    []*Condition           `protobuf:"bytes,4,rep,name=conditions,proto3" json:"conditions,omitempty"`
+	Filters       []*ValuedFilter        `protobuf:"bytes,5,rep,name=filters,proto3" json:"filters,omitempty"`
+	Assigns       []*Assigner            `protobuf:"bytes,6,rep,name=assigns,proto3" json:"assigns,omitempty"`
+	ClientIneq    bool                   `protobuf:"varint,7,opt,name=client_ineq,json=clientIneq,proto3" json:"client_ineq,omitempty"`
+	MaxScan       int64                  `protobuf:"varint,8,opt,name=max_scan,json=maxScan,proto3" json:"max_scan,omitempty"`
+	BatchSize     int64                  `protobuf:"varint,9,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	Geo           *GeoFilter             `protobuf:"bytes,10,opt,name=geo,proto3" json:"geo,omitempty"`
+	Cursor        string                 `protobuf:"bytes,11,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	KeyTieBreaker bool                   `protobuf:"varint,12,opt,name=key_tie_breaker,json=keyTieBreaker,proto3" json:"key_tie_breaker,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryBuilder) Reset() {
+	*x = QueryBuilder{}
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryBuilder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryBuilder) ProtoMessage() {}
+
+func (x *QueryBuilder) ProtoReflect() protoreflect.Message {
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryBuilder.ProtoReflect.Descriptor instead.
+func (*QueryBuilder) Descriptor() ([]byte, []int) {
+	return file_querybuilder_v1_querybuilder_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *QueryBuilder) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *QueryBuilder) GetIgnored() []string {
+	if x != nil {
+		return x.Ignored
+	}
+	return nil
+}
+
+func (x *QueryBuilder) GetSortFields() []string {
+	if x != nil {
+		return x.SortFields
+	}
+	return nil
+}
+
+func (x *QueryBuilder) GetConditions() []*Condition {
+	if x != nil {
+		return x.Conditions
+	}
+	return nil
+}
+
+func (x *QueryBuilder) GetFilters() []*ValuedFilter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *QueryBuilder) GetAssigns() []*Assigner {
+	if x != nil {
+		return x.Assigns
+	}
+	return nil
+}
+
+func (x *QueryBuilder) GetClientIneq() bool {
+	if x != nil {
+		return x.ClientIneq
+	}
+	return false
+}
+
+func (x *QueryBuilder) GetMaxScan() int64 {
+	if x != nil {
+		return x.MaxScan
+	}
+	return 0
+}
+
+func (x *QueryBuilder) GetBatchSize() int64 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *QueryBuilder) GetGeo() *GeoFilter {
+	if x != nil {
+		return x.Geo
+	}
+	return nil
+}
+
+func (x *QueryBuilder) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *QueryBuilder) GetKeyTieBreaker() bool {
+	if x != nil {
+		return x.KeyTieBreaker
+	}
+	return false
+}
+
+type Condition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Ope           Operator               `protobuf:"varint,2,opt,name=ope,proto3,enum=querybuilder.v1.Operator" json:"ope,omitempty"`
+	Value         *Value                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Condition) Reset() {
+	*x = Condition{}
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Condition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Condition) ProtoMessage() {}
+
+func (x *Condition) ProtoReflect() protoreflect.Message {
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Condition.ProtoReflect.Descriptor instead.
+func (*Condition) Descriptor() ([]byte, []int) {
+	return file_querybuilder_v1_querybuilder_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Condition) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Condition) GetOpe() Operator {
+	if x != nil {
+		return x.Ope
+	}
+	return Operator_OPERATOR_UNSPECIFIED
+}
+
+func (x *Condition) GetValue() *Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type Assigner struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Value         *Value                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Assigner) Reset() {
+	*x = Assigner{}
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Assigner) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Assigner) ProtoMessage() {}
+
+func (x *Assigner) ProtoReflect() protoreflect.Message {
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Assigner.ProtoReflect.Descriptor instead.
+func (*Assigner) Descriptor() ([]byte, []int) {
+	return file_querybuilder_v1_querybuilder_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Assigner) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Assigner) GetValue() *Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type ValuedFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value         int64                  `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValuedFilter) Reset() {
+	*x = ValuedFilter{}
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValuedFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValuedFilter) ProtoMessage() {}
+
+func (x *ValuedFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValuedFilter.ProtoReflect.Descriptor instead.
+func (*ValuedFilter) Descriptor() ([]byte, []int) {
+	return file_querybuilder_v1_querybuilder_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ValuedFilter) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ValuedFilter) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+type GeoFilter struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Field          string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Lat            float64                `protobuf:"fixed64,2,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lng            float64                `protobuf:"fixed64,3,opt,name=lng,proto3" json:"lng,omitempty"`
+	Radius         float64                `protobuf:"fixed64,4,opt,name=radius,proto3" json:"radius,omitempty"`
+	SortByDistance bool                   `protobuf:"varint,5,opt,name=sort_by_distance,json=sortByDistance,proto3" json:"sort_by_distance,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GeoFilter) Reset() {
+	*x = GeoFilter{}
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GeoFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GeoFilter) ProtoMessage() {}
+
+func (x *GeoFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GeoFilter.ProtoReflect.Descriptor instead.
+func (*GeoFilter) Descriptor() ([]byte, []int) {
+	return file_querybuilder_v1_querybuilder_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GeoFilter) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *GeoFilter) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *GeoFilter) GetLng() float64 {
+	if x != nil {
+		return x.Lng
+	}
+	return 0
+}
+
+func (x *GeoFilter) GetRadius() float64 {
+	if x != nil {
+		return x.Radius
+	}
+	return 0
+}
+
+func (x *GeoFilter) GetSortByDistance() bool {
+	if x != nil {
+		return x.SortByDistance
+	}
+	return false
+}
+
+// Value is a value of a property. Integers and floats are held in 64 bits.
+type Value struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Value_NullValue
+	//	*Value_IntValue
+	//	*Value_DoubleValue
+	//	*Value_StringValue
+	//	*Value_BoolValue
+	//	*Value_TimestampValue
+	//	*Value_KeyValue
+	//	*Value_BytesValue
+	//	*Value_ArrayValue
+	//	*Value_GeoPointValue
+	//	*Value_ParamValue
+	Kind          isValue_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_querybuilder_v1_querybuilder_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Value) GetKind() isValue_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Value) GetNullValue() structpb.NullValue {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_NullValue); ok {
+			return x.NullValue
+		}
+	}
+	return structpb.NullValue(0)
+}
+
+func (x *Value) GetIntValue() int64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_IntValue); ok {
+			return x.IntValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetDoubleValue() float64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_DoubleValue); ok {
+			return x.DoubleValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetStringValue() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_StringValue); ok {
+			return x.StringValue
+		}
+	}
+	return ""
+}
+
+func (x *Value) GetBoolValue() bool {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_BoolValue); ok {
+			return x.BoolValue
+		}
+	}
+	return false
+}
+
+func (x *Value) GetTimestampValue() *timestamppb.Timestamp {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_TimestampValue); ok {
+			return x.TimestampValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetKeyValue() *Key {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_KeyValue); ok {
+			return x.KeyValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetBytesValue() []byte {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_BytesValue); ok {
+			return x.BytesValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetArrayValue() *ArrayValue {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_ArrayValue); ok {
+			return x.ArrayValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetGeoPointValue() *GeoPoint {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_GeoPointValue); ok {
+			return x.GeoPointValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetParamValue() *Param {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_ParamValue); ok {
+			return x.ParamValue
+		}
+	}
+	return nil
+}
+
+type isValue_Kind interface {
+	isValue_Kind()
+}
+
+type Value_NullValue struct {
+	NullValue structpb.NullValue `protobuf:"varint,1,opt,name=null_value,json=nullValue,proto3,enum=google.protobuf.NullValue,oneof"`
+}
+
+type Value_IntValue struct {
+	IntValue int64 `protobuf:"varint,2,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+type Value_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,3,opt,name=double_value,json=doubleValue,proto3,oneof"`
+}
+
+type Value_StringValue struct {
+	StringValue string `protobuf:"bytes,4,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type Value_BoolValue struct {
+	BoolValue bool `protobuf:"varint,5,opt,name=bool_value,json=boolValue,proto3,oneof"`
+}
+
+type Value_TimestampValue struct {
+	TimestampValue *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp_value,json=timestampValue,proto3,oneof"`
+}
+
+type Value_KeyValue struct {
+	KeyValue *Key `protobuf:"bytes,7,opt,name=key_value,json=keyValue,proto3,oneof"`
+}
+
+type Value_BytesValue struct {
+	BytesValue []byte `protobuf:"bytes,8,opt,name=bytes_value,json=bytesValue,proto3,oneof"`
+}
+
+type Value_ArrayValue struct {
+	ArrayValue *ArrayValue `protobuf:"bytes,9,opt,name=array_value,json=arrayValue,proto3,oneof"`
+}
+
+type Value_GeoPointValue struct {
+	GeoPointValue *GeoPoint `protobuf:"bytes,10,opt,name=geo_point_value,json=geoPointValue,proto3,oneof"`
+}
+
+type Value_ParamValue struct {
+	ParamValue *Param `protobuf:"bytes,11,opt,name=param_value,json=paramValue,proto3,oneof"`
+}
+
+func (*Value_NullValue) isValue_Kind() {}
+
+func (*Value_IntValue) isValue_Kind() {}
+
+func (*Value_DoubleValue) isValue_Kind() {}
+
+func (*Value_StringValue) isValue_Kind() {}
+
+func (*Value_BoolValue) isValue_Kind() {}
+
+func (*Value_TimestampValue) isValue_Kind() {}
+
+func (*Value_KeyValue) isValue_Kind() {}
+
+func (*Value_BytesValue) isValue_Kind() {}
+
+func (*Value_ArrayValue) isValue_Kind() {}
+
+func (*Value_GeoPointValue) isValue_Kind() {}
+
+func (*Value_ParamValue) isValue_Kind() {}
+
+type ArrayValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []*Value               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArrayValue) Reset() {
+	*x = ArrayValue{}
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArrayValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArrayValue) ProtoMessage() {}
+
+func (x *ArrayValue) ProtoReflect() protoreflect.Message {
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArrayValue.ProtoReflect.Descriptor instead.
+func (*ArrayValue) Descriptor() ([]byte, []int) {
+	return file_querybuilder_v1_querybuilder_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ArrayValue) GetValues() []*Value {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type Key struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace     string                 `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Parent        *Key                   `protobuf:"bytes,5,opt,name=parent,proto3" json:"parent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Key) Reset() {
+	*x = Key{}
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Key) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Key) ProtoMessage() {}
+
+func (x *Key) ProtoReflect() protoreflect.Message {
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Key.ProtoReflect.Descriptor instead.
+func (*Key) Descriptor() ([]byte, []int) {
+	return file_querybuilder_v1_querybuilder_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Key) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Key) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Key) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Key) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *Key) GetParent() *Key {
+	if x != nil {
+		return x.Parent
+	}
+	return nil
+}
+
+type GeoPoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lat           float64                `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lng           float64                `protobuf:"fixed64,2,opt,name=lng,proto3" json:"lng,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GeoPoint) Reset() {
+	*x = GeoPoint{}
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GeoPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GeoPoint) ProtoMessage() {}
+
+func (x *GeoPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GeoPoint.ProtoReflect.Descriptor instead.
+func (*GeoPoint) Descriptor() ([]byte, []int) {
+	return file_querybuilder_v1_querybuilder_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GeoPoint) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *GeoPoint) GetLng() float64 {
+	if x != nil {
+		return x.Lng
+	}
+	return 0
+}
+
+// Param is a placeholder of a value given by QueryBuilder.Bind.
+type Param struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Param) Reset() {
+	*x = Param{}
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Param) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Param) ProtoMessage() {}
+
+func (x *Param) ProtoReflect() protoreflect.Message {
+	mi := &file_querybuilder_v1_querybuilder_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Param.ProtoReflect.Descriptor instead.
+func (*Param) Descriptor() ([]byte, []int) {
+	return file_querybuilder_v1_querybuilder_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Param) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Param) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+var File_querybuilder_v1_querybuilder_proto protoreflect.FileDescriptor
+
+const file_querybuilder_v1_querybuilder_proto_rawDesc = "" +
+	"\n" +
+	"\"querybuilder/v1/querybuilder.proto\x12\x0fquerybuilder.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd4\x03\n" +
+	"\fQueryBuilder\x12\x16\n" +
+	"\x06fields\x18\x01 \x03(\tR\x06fields\x12\x18\n" +
+	"\aignored\x18\x02 \x03(\tR\aignored\x12\x1f\n" +
+	"\vsort_fields\x18\x03 \x03(\tR\n" +
+	"sortFields\x12:\n" +
+	"\n" +
+	"conditions\x18\x04 \x03(\v2\x1a.querybuilder.v1.ConditionR\n" +
+	"conditions\x127\n" +
+	"\afilters\x18\x05 \x03(\v2\x1d.querybuilder.v1.ValuedFilterR\afilters\x123\n" +
+	"\aassigns\x18\x06 \x03(\v2\x19.querybuilder.v1.AssignerR\aassigns\x12\x1f\n" +
+	"\vclient_ineq\x18\a \x01(\bR\n" +
+	"clientIneq\x12\x19\n" +
+	"\bmax_scan\x18\b \x01(\x03R\amaxScan\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\t \x01(\x03R\tbatchSize\x12,\n" +
+	"\x03geo\x18\n" +
+	" \x01(\v2\x1a.querybuilder.v1.GeoFilterR\x03geo\x12\x16\n" +
+	"\x06cursor\x18\v \x01(\tR\x06cursor\x12&\n" +
+	"\x0fkey_tie_breaker\x18\f \x01(\bR\rkeyTieBreaker\"|\n" +
+	"\tCondition\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12+\n" +
+	"\x03ope\x18\x02 \x01(\x0e2\x19.querybuilder.v1.OperatorR\x03ope\x12,\n" +
+	"\x05value\x18\x03 \x01(\v2\x16.querybuilder.v1.ValueR\x05value\"N\n" +
+	"\bAssigner\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.querybuilder.v1.ValueR\x05value\"8\n" +
+	"\fValuedFilter\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value\"\x87\x01\n" +
+	"\tGeoFilter\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x10\n" +
+	"\x03lat\x18\x02 \x01(\x01R\x03lat\x12\x10\n" +
+	"\x03lng\x18\x03 \x01(\x01R\x03lng\x12\x16\n" +
+	"\x06radius\x18\x04 \x01(\x01R\x06radius\x12(\n" +
+	"\x10sort_by_distance\x18\x05 \x01(\bR\x0esortByDistance\"\xb5\x04\n" +
+	"\x05Value\x12;\n" +
+	"\n" +
+	"null_value\x18\x01 \x01(\x0e2\x1a.google.protobuf.NullValueH\x00R\tnullValue\x12\x1d\n" +
+	"\tint_value\x18\x02 \x01(\x03H\x00R\bintValue\x12#\n" +
+	"\fdouble_value\x18\x03 \x01(\x01H\x00R\vdoubleValue\x12#\n" +
+	"\fstring_value\x18\x04 \x01(\tH\x00R\vstringValue\x12\x1f\n" +
+	"\n" +
+	"bool_value\x18\x05 \x01(\bH\x00R\tboolValue\x12E\n" +
+	"\x0ftimestamp_value\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampH\x00R\x0etimestampValue\x123\n" +
+	"\tkey_value\x18\a \x01(\v2\x14.querybuilder.v1.KeyH\x00R\bkeyValue\x12!\n" +
+	"\vbytes_value\x18\b \x01(\fH\x00R\n" +
+	"bytesValue\x12>\n" +
+	"\varray_value\x18\t \x01(\v2\x1b.querybuilder.v1.ArrayValueH\x00R\n" +
+	"arrayValue\x12C\n" +
+	"\x0fgeo_point_value\x18\n" +
+	" \x01(\v2\x19.querybuilder.v1.GeoPointH\x00R\rgeoPointValue\x129\n" +
+	"\vparam_value\x18\v \x01(\v2\x16.querybuilder.v1.ParamH\x00R\n" +
+	"paramValueB\x06\n" +
+	"\x04kind\"<\n" +
+	"\n" +
+	"ArrayValue\x12.\n" +
+	"\x06values\x18\x01 \x03(\v2\x16.querybuilder.v1.ValueR\x06values\"\x89\x01\n" +
+	"\x03Key\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1c\n" +
+	"\tnamespace\x18\x04 \x01(\tR\tnamespace\x12,\n" +
+	"\x06parent\x18\x05 \x01(\v2\x14.querybuilder.v1.KeyR\x06parent\".\n" +
+	"\bGeoPoint\x12\x10\n" +
+	"\x03lat\x18\x01 \x01(\x01R\x03lat\x12\x10\n" +
+	"\x03lng\x18\x02 \x01(\x01R\x03lng\"/\n" +
+	"\x05Param\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type*\x83\x01\n" +
+	"\bOperator\x12\x18\n" +
+	"\x14OPERATOR_UNSPECIFIED\x10\x00\x12\r\n" +
+	"\tLESS_THAN\x10\x01\x12\x16\n" +
+	"\x12LESS_THAN_OR_EQUAL\x10\x02\x12\x10\n" +
+	"\fGREATER_THAN\x10\x03\x12\x19\n" +
+	"\x15GREATER_THAN_OR_EQUAL\x10\x04\x12\t\n" +
+	"\x05EQUAL\x10\x05B,Z*github.com/akm/querybuilder/querybuilderpbb\x06proto3"
+
+var (
+	file_querybuilder_v1_querybuilder_proto_rawDescOnce sync.Once
+	file_querybuilder_v1_querybuilder_proto_rawDescData []byte
+)
+
+func file_querybuilder_v1_querybuilder_proto_rawDescGZIP() []byte {
+	file_querybuilder_v1_querybuilder_proto_rawDescOnce.Do(func() {
+		file_querybuilder_v1_querybuilder_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_querybuilder_v1_querybuilder_proto_rawDesc), len(file_querybuilder_v1_querybuilder_proto_rawDesc)))
+	})
+	return file_querybuilder_v1_querybuilder_proto_rawDescData
+}
+
+var file_querybuilder_v1_querybuilder_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_querybuilder_v1_querybuilder_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_querybuilder_v1_querybuilder_proto_goTypes = []any{
+	(Operator)(0),                 // 0: querybuilder.v1.Operator
+	(*QueryBuilder)(nil),          // 1: querybuilder.v1.QueryBuilder
+	(*Condition)(nil),             // 2: querybuilder.v1.Condition
+	(*Assigner)(nil),              // 3: querybuilder.v1.Assigner
+	(*ValuedFilter)(nil),          // 4: querybuilder.v1.ValuedFilter
+	(*GeoFilter)(nil),             // 5: querybuilder.v1.GeoFilter
+	(*Value)(nil),                 // 6: querybuilder.v1.Value
+	(*ArrayValue)(nil),            // 7: querybuilder.v1.ArrayValue
+	(*Key)(nil),                   // 8: querybuilder.v1.Key
+	(*GeoPoint)(nil),              // 9: querybuilder.v1.GeoPoint
+	(*Param)(nil),                 // 10: querybuilder.v1.Param
+	(structpb.NullValue)(0),       // 11: google.protobuf.NullValue
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_querybuilder_v1_querybuilder_proto_depIdxs = []int32{
+	2,  // 0: querybuilder.v1.QueryBuilder.conditions:type_name -> querybuilder.v1.Condition
+	4,  // 1: querybuilder.v1.QueryBuilder.filters:type_name -> querybuilder.v1.ValuedFilter
+	3,  // 2: querybuilder.v1.QueryBuilder.assigns:type_name -> querybuilder.v1.Assigner
+	5,  // 3: querybuilder.v1.QueryBuilder.geo:type_name -> querybuilder.v1.GeoFilter
+	0,  // 4: querybuilder.v1.Condition.ope:type_name -> querybuilder.v1.Operator
+	6,  // 5: querybuilder.v1.Condition.value:type_name -> querybuilder.v1.Value
+	6,  // 6: querybuilder.v1.Assigner.value:type_name -> querybuilder.v1.Value
+	11, // 7: querybuilder.v1.Value.null_value:type_name -> google.protobuf.NullValue
+	12, // 8: querybuilder.v1.Value.timestamp_value:type_name -> google.protobuf.Timestamp
+	8,  // 9: querybuilder.v1.Value.key_value:type_name -> querybuilder.v1.Key
+	7,  // 10: querybuilder.v1.Value.array_value:type_name -> querybuilder.v1.ArrayValue
+	9,  // 11: querybuilder.v1.Value.geo_point_value:type_name -> querybuilder.v1.GeoPoint
+	10, // 12: querybuilder.v1.Value.param_value:type_name -> querybuilder.v1.Param
+	6,  // 13: querybuilder.v1.ArrayValue.values:type_name -> querybuilder.v1.Value
+	8,  // 14: querybuilder.v1.Key.parent:type_name -> querybuilder.v1.Key
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_querybuilder_v1_querybuilder_proto_init() }
+func file_querybuilder_v1_querybuilder_proto_init() {
+	if File_querybuilder_v1_querybuilder_proto != nil {
+		return
+	}
+	file_querybuilder_v1_querybuilder_proto_msgTypes[5].OneofWrappers = []any{
+		(*Value_NullValue)(nil),
+		(*Value_IntValue)(nil),
+		(*Value_DoubleValue)(nil),
+		(*Value_StringValue)(nil),
+		(*Value_BoolValue)(nil),
+		(*Value_TimestampValue)(nil),
+		(*Value_KeyValue)(nil),
+		(*Value_BytesValue)(nil),
+		(*Value_ArrayValue)(nil),
+		(*Value_GeoPointValue)(nil),
+		(*Value_ParamValue)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_querybuilder_v1_querybuilder_proto_rawDesc), len(file_querybuilder_v1_querybuilder_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_querybuilder_v1_querybuilder_proto_goTypes,
+		DependencyIndexes: file_querybuilder_v1_querybuilder_proto_depIdxs,
+		EnumInfos:         file_querybuilder_v1_querybuilder_proto_enumTypes,
+		MessageInfos:      file_querybuilder_v1_querybuilder_proto_msgTypes,
+	}.Build()
+	File_querybuilder_v1_querybuilder_proto = out.File
+	file_querybuilder_v1_querybuilder_proto_goTypes = nil
+	file_querybuilder_v1_querybuilder_proto_depIdxs = nil
+}
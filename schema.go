@@ -0,0 +1,277 @@
+package querybuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const SchemaID = "https://github.com/akm/querybuilder/querybuilder.schema.json"
+
+var FilterNames = Strings{"offset", "limit"}
+
+var ValueTypes = Strings{TimeValueType}
+
+// JSONSchema returns the JSON Schema of the JSON which QueryBuilder is
+// marshaled to. It's generated from the struct tags of QueryBuilder and the
+// types it contains.
+func JSONSchema() map[string]interface{} {
+	g := &schemaGenerator{defs: map[string]interface{}{}}
+	r := g.object(reflect.TypeOf(QueryBuilder{}))
+	r["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	r["$id"] = SchemaID
+	r["title"] = "QueryBuilder"
+	r["$defs"] = g.defs
+	return r
+}
+
+// schemaShapes are the types which are marshaled by MarshalJSON to the JSON
+// of other types.
+var schemaShapes = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(Condition{}): reflect.TypeOf(conditionJSON{}),
+	reflect.TypeOf(Assigner{}):  reflect.TypeOf(assignerJSON{}),
+}
+
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = nil // Placeholder against recursion
+			g.defs[name] = g.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + name}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Interface:
+		return map[string]interface{}{}
+	default:
+		if isIntKind(t.Kind()) {
+			return map[string]interface{}{"type": "integer"}
+		}
+		panic(fmt.Sprintf("Unsupported type for JSON Schema: %v", t))
+	}
+}
+
+func (g *schemaGenerator) object(t reflect.Type) map[string]interface{} {
+	if shape, ok := schemaShapes[t]; ok {
+		t = shape
+	}
+	props := map[string]interface{}{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("json")
+		if !ok || tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		props[parts[0]] = g.schema(sf.Type)
+		if len(parts) < 2 || parts[1] != "omitempty" {
+			required = append(required, parts[0])
+		}
+	}
+	r := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		r["required"] = required
+	}
+
+	switch t {
+	case reflect.TypeOf(conditionJSON{}):
+		opes := []string{}
+		for k := range OperatorMap {
+			opes = append(opes, k)
+		}
+		sort.Strings(opes)
+		props["ope"] = map[string]interface{}{"type": "string", "enum": opes}
+		props["type"] = map[string]interface{}{"type": "string", "enum": []string(ValueTypes)}
+	case reflect.TypeOf(assignerJSON{}):
+		props["type"] = map[string]interface{}{"type": "string", "enum": []string(ValueTypes)}
+	case reflect.TypeOf(ValuedFilter{}):
+		props["name"] = map[string]interface{}{"type": "string", "enum": []string(FilterNames)}
+	}
+	return r
+}
+
+// JSONSchemaBytes returns JSONSchema in indented JSON.
+func JSONSchemaBytes() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(JSONSchema()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type ValidationError struct {
+	Pointer string // JSON Pointer to the invalid value
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pointer, e.Message)
+}
+
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+var (
+	jsonSchemaOnce sync.Once
+	jsonSchema     map[string]interface{}
+)
+
+// ValidateJSON validates data against JSONSchema. It returns
+// ValidationErrors if data doesn't conform to the schema.
+func ValidateJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	jsonSchemaOnce.Do(func() {
+		// Through JSON to be the same as the schema loaded from the file
+		b, err := json.Marshal(JSONSchema())
+		if err != nil {
+			panic(err)
+		}
+		if err := json.Unmarshal(b, &jsonSchema); err != nil {
+			panic(err)
+		}
+	})
+	sv := &schemaValidator{root: jsonSchema}
+	sv.validate(jsonSchema, v, "")
+	if len(sv.errors) > 0 {
+		return sv.errors
+	}
+	return nil
+}
+
+// schemaValidator supports the keywords which JSONSchema uses.
+type schemaValidator struct {
+	root   map[string]interface{}
+	errors ValidationErrors
+}
+
+func (sv *schemaValidator) fail(ptr, format string, args ...interface{}) {
+	sv.errors = append(sv.errors, &ValidationError{Pointer: ptr, Message: fmt.Sprintf(format, args...)})
+}
+
+func (sv *schemaValidator) validate(schema map[string]interface{}, v interface{}, ptr string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/$defs/")
+		schema = sv.root["$defs"].(map[string]interface{})[name].(map[string]interface{})
+	}
+	if typ, ok := schema["type"].(string); ok && !jsonTypeMatch(typ, v) {
+		sv.fail(ptr, "must be %s but was %s", typ, jsonTypeOf(v))
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if e == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			sv.fail(ptr, "must be one of %v but was %v", enum, v)
+		}
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		props, _ := schema["properties"].(map[string]interface{})
+		if req, ok := schema["required"].([]interface{}); ok {
+			for _, name := range req {
+				if _, ok := x[name.(string)]; !ok {
+					sv.fail(ptr, "%s is required", name)
+				}
+			}
+		}
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p, ok := props[k].(map[string]interface{})
+			if !ok {
+				if schema["additionalProperties"] == false {
+					sv.fail(ptr+"/"+escapeJSONPointer(k), "unknown property")
+				}
+				continue
+			}
+			sv.validate(p, x[k], ptr+"/"+escapeJSONPointer(k))
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, e := range x {
+				sv.validate(items, e, ptr+"/"+strconv.Itoa(i))
+			}
+		}
+	}
+}
+
+func jsonTypeMatch(typ string, v interface{}) bool {
+	if typ == "integer" {
+		f, ok := v.(float64)
+		return ok && f == float64(int64(f))
+	}
+	if typ == "number" {
+		_, ok := v.(float64)
+		return ok
+	}
+	return jsonTypeOf(v) == typ
+}
+
+func jsonTypeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func escapeJSONPointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
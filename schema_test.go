@@ -0,0 +1,116 @@
+package querybuilder
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var updateSchema = flag.Bool("update-schema", false, "update querybuilder.schema.json")
+
+const schemaFile = "querybuilder.schema.json"
+
+func TestJSONSchemaFile(t *testing.T) {
+	b, err := JSONSchemaBytes()
+	assert.NoError(t, err)
+	if *updateSchema {
+		assert.NoError(t, os.WriteFile(schemaFile, b, 0644))
+	}
+	expected, err := os.ReadFile(schemaFile)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(b), "Run go test -run TestJSONSchemaFile -update-schema")
+}
+
+func TestJSONSchemaSync(t *testing.T) {
+	schema := JSONSchema()
+	defs := schema["$defs"].(map[string]interface{})
+
+	jsonNames := func(t reflect.Type) []string {
+		r := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			if tag, ok := t.Field(i).Tag.Lookup("json"); ok {
+				r = append(r, strings.Split(tag, ",")[0])
+			}
+		}
+		return r
+	}
+	propNames := func(s map[string]interface{}) []string {
+		r := []string{}
+		for k := range s["properties"].(map[string]interface{}) {
+			r = append(r, k)
+		}
+		return r
+	}
+
+	assert.ElementsMatch(t, jsonNames(reflect.TypeOf(QueryBuilder{})), propNames(schema))
+	for name, typ := range map[string]interface{}{
+		"Condition":    conditionJSON{},
+		"Assigner":     assignerJSON{},
+		"ValuedFilter": ValuedFilter{},
+		"GeoFilter":    GeoFilter{},
+	} {
+		def, ok := defs[name].(map[string]interface{})
+		if assert.True(t, ok, name) {
+			assert.ElementsMatch(t, jsonNames(reflect.TypeOf(typ)), propNames(def), name)
+		}
+	}
+	assert.Equal(t, 4, len(defs))
+
+	// The shapes must have the fields of the types they represent
+	for typ, shape := range schemaShapes {
+		for _, name := range jsonNames(typ) {
+			assert.Contains(t, jsonNames(shape), name, typ.Name())
+		}
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	for _, path := range []string{"builder_test/simple_eq.json", "builder_test/no_condition.json"} {
+		b, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.NoError(t, ValidateJSON(b), path)
+	}
+
+	{ // Marshaled builders are valid
+		b := New("Int1").Eq("Str1", "a").Gte("At", time.Now()).Eq("Int2", Placeholder("p")).Desc("Int1").Offset(1).Limit(2)
+		b.Geo = &GeoFilter{Field: "Location", Lat: 1, Lng: 2, Radius: 3}
+		data, err := json.Marshal(b)
+		assert.NoError(t, err)
+		assert.NoError(t, ValidateJSON(data))
+	}
+
+	pointers := func(err error) []string {
+		r := []string{}
+		if errs, ok := err.(ValidationErrors); ok {
+			for _, e := range errs {
+				r = append(r, e.Pointer)
+			}
+		}
+		return r
+	}
+
+	{
+		err := ValidateJSON([]byte(`{"conditions":[{"field":"Int1","ope":"=","value":1},{"field":"Int2","ope":"~","value":1}]}`))
+		assert.Equal(t, []string{"/conditions/1/ope"}, pointers(err))
+		assert.Contains(t, err.Error(), "/conditions/1/ope: must be one of")
+	}
+	{
+		err := ValidateJSON([]byte(`{"filters":[{"name":"skip","value":1},{"name":"limit","value":1.5}]}`))
+		assert.Equal(t, []string{"/filters/0/name", "/filters/1/value"}, pointers(err))
+	}
+	{
+		err := ValidateJSON([]byte(`{"fields":"Int1","unknown":1,"assigns":[{"value":1}]}`))
+		assert.Equal(t, []string{"/assigns/0", "/fields", "/unknown"}, pointers(err))
+	}
+	{
+		err := ValidateJSON([]byte(`{`))
+		assert.Error(t, err)
+		assert.Empty(t, pointers(err))
+	}
+}
@@ -0,0 +1,118 @@
+package querybuilder
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const InstrumentationName = "github.com/akm/querybuilder"
+
+// Telemetry records spans and metrics of queries run by Executor.
+// Condition values are never recorded.
+type Telemetry struct {
+	tracer      trace.Tracer
+	duration    metric.Float64Histogram
+	resultCount metric.Int64Histogram
+}
+
+// NewTelemetry uses the global providers of otel if tp or mp is nil.
+func NewTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) (*Telemetry, error) {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	meter := mp.Meter(InstrumentationName)
+	duration, err := meter.Float64Histogram("querybuilder.query.duration",
+		metric.WithDescription("Duration of queries"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	resultCount, err := meter.Int64Histogram("querybuilder.query.result_count",
+		metric.WithDescription("Number of entities returned or counted by queries"),
+		metric.WithUnit("{entity}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Telemetry{
+		tracer:      tp.Tracer(InstrumentationName),
+		duration:    duration,
+		resultCount: resultCount,
+	}, nil
+}
+
+func QueryAttributes(e *Executor, qb *QueryBuilder) []attribute.KeyValue {
+	fields := []string{}
+	opes := []string{}
+	for _, c := range qb.Conditions {
+		fields = append(fields, c.Field)
+		opes = append(opes, c.Ope.String())
+	}
+	limit, _ := qb.IntFilterValue("limit")
+	offset, _ := qb.IntFilterValue("offset")
+	return []attribute.KeyValue{
+		attribute.String("querybuilder.kind", e.Kind),
+		attribute.String("querybuilder.namespace", e.Namespace),
+		attribute.StringSlice("querybuilder.condition.fields", fields),
+		attribute.StringSlice("querybuilder.condition.operators", opes),
+		attribute.StringSlice("querybuilder.sort", qb.ListSortFields()),
+		attribute.StringSlice("querybuilder.projection", qb.ProjectFields()),
+		attribute.Int("querybuilder.limit", limit),
+		attribute.Int("querybuilder.offset", offset),
+	}
+}
+
+// observe records the span and the metrics of the query run by f.
+func (t *Telemetry) observe(ctx context.Context, name string, e *Executor, qb *QueryBuilder, f func(context.Context) (int, error)) error {
+	return t.record(ctx, name, e, qb, true, f)
+}
+
+// span records only the span of f, which doesn't run a query.
+func (t *Telemetry) span(ctx context.Context, name string, e *Executor, qb *QueryBuilder, f func(context.Context) (int, error)) error {
+	return t.record(ctx, name, e, qb, false, f)
+}
+
+func (t *Telemetry) record(ctx context.Context, name string, e *Executor, qb *QueryBuilder, metrics bool, f func(context.Context) (int, error)) error {
+	if t == nil {
+		_, err := f(ctx)
+		return err
+	}
+	attrs := QueryAttributes(e, qb)
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	defer span.End()
+
+	started := time.Now()
+	c, err := f(ctx)
+	elapsed := time.Since(started)
+
+	span.SetAttributes(
+		attribute.Int("querybuilder.result_count", c),
+		attribute.Float64("querybuilder.duration_ms", float64(elapsed)/float64(time.Millisecond)),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if !metrics {
+		return err
+	}
+
+	opts := metric.WithAttributes(
+		attribute.String("querybuilder.operation", name),
+		attribute.String("querybuilder.kind", e.Kind),
+		attribute.Bool("querybuilder.error", err != nil),
+	)
+	t.duration.Record(ctx, float64(elapsed)/float64(time.Millisecond), opts)
+	t.resultCount.Record(ctx, int64(c), opts)
+	return err
+}
@@ -0,0 +1,83 @@
+package querybuilder
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTelemetry(t *testing.T) {
+	ctx := context.Background()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	telemetry, err := NewTelemetry(tp, mp)
+	assert.NoError(t, err)
+
+	e := NewExecutor(&fakeClient{entities: Entities}, Kind4Test)
+	e.Telemetry = telemetry
+
+	b := New("Int1", "Str1", "Str2").Eq("Str2", "secret-value").Gte("Int1", 2).Limit(10).TieBreakByKey()
+	var entities []*Entity4Test
+	_, err = e.GetAll(ctx, b, &entities)
+	assert.NoError(t, err)
+	_, err = e.Count(ctx, b)
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	names := []string{}
+	for _, s := range spans {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"querybuilder.Assign", "querybuilder.GetAll", "querybuilder.Count"}, names)
+
+	{
+		getAll := spans[1]
+		assert.Equal(t, getAll.SpanContext.SpanID(), spans[0].Parent.SpanID())
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, kv := range getAll.Attributes {
+			attrs[kv.Key] = kv.Value
+		}
+		assert.Equal(t, Kind4Test, attrs["querybuilder.kind"].AsString())
+		assert.Equal(t, []string{"Str2", "Int1"}, attrs["querybuilder.condition.fields"].AsStringSlice())
+		assert.Equal(t, []string{"=", ">="}, attrs["querybuilder.condition.operators"].AsStringSlice())
+		assert.Equal(t, []string{"Int1", KeyField}, attrs["querybuilder.sort"].AsStringSlice())
+		assert.Equal(t, []string{"Int1", "Str1"}, attrs["querybuilder.projection"].AsStringSlice())
+		assert.Equal(t, int64(10), attrs["querybuilder.limit"].AsInt64())
+		assert.Equal(t, int64(len(Entities)), attrs["querybuilder.result_count"].AsInt64())
+		_, ok := attrs["querybuilder.duration_ms"]
+		assert.True(t, ok)
+		for _, v := range attrs {
+			assert.NotContains(t, v.Emit(), "secret-value")
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(ctx, &rm))
+	histograms := map[string]int{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					histograms[m.Name] += int(dp.Count)
+				}
+			case metricdata.Histogram[int64]:
+				for _, dp := range data.DataPoints {
+					histograms[m.Name] += int(dp.Count)
+				}
+			}
+		}
+	}
+	// Assign is recorded only as a span
+	assert.Equal(t, map[string]int{"querybuilder.query.duration": 2, "querybuilder.query.result_count": 2}, histograms)
+}
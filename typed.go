@@ -0,0 +1,260 @@
+package querybuilder
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"cloud.google.com/go/datastore"
+)
+
+const KeyField = "__key__"
+
+// TypedBuilder is a QueryBuilder bound to the entity type T.
+// Field paths are validated against T and values are converted to the
+// types of the fields. The first error is kept and returned by Err.
+type TypedBuilder[T any] struct {
+	Builder *QueryBuilder
+	err     error
+}
+
+func For[T any](fields ...string) *TypedBuilder[T] {
+	b := &TypedBuilder[T]{Builder: New()}
+	for _, f := range fields {
+		if _, _, err := b.resolve(f); err != nil {
+			b.setErr(err)
+			continue
+		}
+		b.Builder.Fields = append(b.Builder.Fields, f)
+	}
+	return b
+}
+
+func (b *TypedBuilder[T]) Err() error {
+	return b.err
+}
+
+func (b *TypedBuilder[T]) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *TypedBuilder[T]) resolve(field string) (string, reflect.Type, error) {
+	return ResolveField(reflect.TypeOf((*T)(nil)).Elem(), field)
+}
+
+func (b *TypedBuilder[T]) coerce(field string, value interface{}) (string, interface{}, bool) {
+	path, t, err := b.resolve(field)
+	if err != nil {
+		b.setErr(err)
+		return "", nil, false
+	}
+	v, err := CoerceValue(value, t)
+	if err != nil {
+		b.setErr(fmt.Errorf("%s: %v", field, err))
+		return "", nil, false
+	}
+	return path, v, true
+}
+
+func (b *TypedBuilder[T]) Eq(field string, value interface{}) *TypedBuilder[T] {
+	if path, v, ok := b.coerce(field, value); ok {
+		b.Builder.EqWithPath(field, path, v)
+	}
+	return b
+}
+
+func (b *TypedBuilder[T]) Ineq(ope Ope, field string, value interface{}) *TypedBuilder[T] {
+	if _, v, ok := b.coerce(field, value); ok {
+		b.Builder.Ineq(ope, field, v)
+	}
+	return b
+}
+
+func (b *TypedBuilder[T]) Lt(field string, value interface{}) *TypedBuilder[T] {
+	return b.Ineq(LT, field, value)
+}
+
+func (b *TypedBuilder[T]) Lte(field string, value interface{}) *TypedBuilder[T] {
+	return b.Ineq(LTE, field, value)
+}
+
+func (b *TypedBuilder[T]) Gt(field string, value interface{}) *TypedBuilder[T] {
+	return b.Ineq(GT, field, value)
+}
+
+func (b *TypedBuilder[T]) Gte(field string, value interface{}) *TypedBuilder[T] {
+	return b.Ineq(GTE, field, value)
+}
+
+func (b *TypedBuilder[T]) Between(field string, from, to interface{}) *TypedBuilder[T] {
+	return b.Gte(field, from).Lt(field, to)
+}
+
+func (b *TypedBuilder[T]) Starts(field, value string) *TypedBuilder[T] {
+	_, t, err := b.resolve(field)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	if t.Kind() != reflect.String {
+		b.setErr(fmt.Errorf("%s is not a string but %v", field, t))
+		return b
+	}
+	b.Builder.Starts(field, value)
+	return b
+}
+
+func (b *TypedBuilder[T]) Asc(field string) *TypedBuilder[T] {
+	return b.AddSort(field)
+}
+
+func (b *TypedBuilder[T]) Desc(field string) *TypedBuilder[T] {
+	return b.AddSort("-" + field)
+}
+
+func (b *TypedBuilder[T]) AddSort(field string) *TypedBuilder[T] {
+	if _, _, err := b.resolve(strings.TrimPrefix(field, "-")); err != nil {
+		b.setErr(err)
+		return b
+	}
+	b.Builder.AddSort(field)
+	return b
+}
+
+func (b *TypedBuilder[T]) Offset(v int) *TypedBuilder[T] {
+	b.Builder.Offset(v)
+	return b
+}
+
+func (b *TypedBuilder[T]) Limit(v int) *TypedBuilder[T] {
+	b.Builder.Limit(v)
+	return b
+}
+
+func (b *TypedBuilder[T]) Build(q *datastore.Query) (*datastore.Query, Assigners, error) {
+	if b.err != nil {
+		return nil, nil, b.err
+	}
+	q, assigns := b.Builder.Build(q)
+	return q, assigns, nil
+}
+
+func (b *TypedBuilder[T]) GetAll(ctx context.Context, e *Executor) ([]*T, []*datastore.Key, error) {
+	if b.err != nil {
+		return nil, nil, b.err
+	}
+	var r []*T
+	keys, err := e.GetAll(ctx, b.Builder, &r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, keys, nil
+}
+
+func (b *TypedBuilder[T]) Count(ctx context.Context, e *Executor) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	return e.Count(ctx, b.Builder)
+}
+
+// ResolveField returns the path of the struct field in t for the property
+// and the type of its values. Elements of slices are the values of
+// multi-valued properties.
+func ResolveField(t reflect.Type, field string) (string, reflect.Type, error) {
+	if field == KeyField {
+		return "", keyType, nil
+	}
+	path := []string{}
+	curr := t
+	for _, name := range strings.Split(field, ".") {
+		curr = valueType(curr)
+		if curr.Kind() != reflect.Struct || curr == timeType || curr == geoPointType {
+			return "", nil, fmt.Errorf("%s is not a struct but %v", strings.Join(path, "."), curr)
+		}
+		sf, ok := findStructField(curr, name)
+		if !ok {
+			return "", nil, fmt.Errorf("%v has no field for %s", curr, field)
+		}
+		path = append(path, sf.Name)
+		curr = sf.Type
+	}
+	return strings.Join(path, "."), valueType(curr), nil
+}
+
+func valueType(t reflect.Type) reflect.Type {
+	for {
+		switch {
+		case t.Kind() == reflect.Ptr && t != keyType:
+			t = t.Elem()
+		case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+func findStructField(t reflect.Type, property string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("datastore"); ok {
+			n := strings.Split(tag, ",")[0]
+			if n == "-" {
+				continue
+			}
+			if n != "" {
+				name = n
+			}
+		}
+		if name == property {
+			return sf, true
+		}
+		if sf.Anonymous && valueType(sf.Type).Kind() == reflect.Struct {
+			if r, ok := findStructField(valueType(sf.Type), property); ok {
+				return r, true
+			}
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// CoerceValue converts value to t without losing information.
+// Param is returned as it is to be bound later.
+func CoerceValue(value interface{}, t reflect.Type) (interface{}, error) {
+	if _, ok := value.(Param); ok {
+		return value, nil
+	}
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("nil is not a value of %v", t)
+	}
+	if v.Type() == t {
+		return value, nil
+	}
+	ok := false
+	switch {
+	case t == timeType || t == geoPointType || t == keyType:
+	case isNumberKind(t.Kind()):
+		ok = isNumberKind(v.Kind())
+	case t.Kind() == reflect.String, t.Kind() == reflect.Bool:
+		ok = v.Kind() == t.Kind()
+	case t.Kind() == reflect.Slice:
+		ok = v.Kind() == reflect.Slice && v.Type().ConvertibleTo(t)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%T can't be converted to %v", value, t)
+	}
+	r := v.Convert(t)
+	if isNumberKind(t.Kind()) && r.Convert(v.Type()).Interface() != value {
+		return nil, fmt.Errorf("%v can't be converted to %v without loss", value, t)
+	}
+	return r.Interface(), nil
+}
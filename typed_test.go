@@ -0,0 +1,66 @@
+package querybuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedBuilder(t *testing.T) {
+	{
+		b := For[Entity4Test]("Int1", "Str1", "EnumA").Eq("EnumA", 2).Gte("Int1", 2.0).Asc("Str1").Limit(3)
+		assert.NoError(t, b.Err())
+		assert.Equal(t, Conditions{{"EnumA", EQ, EnumA2}, {"Int1", GTE, 2}}, b.Builder.Conditions)
+		assert.Equal(t, Strings{"Int1", "Str1"}, b.Builder.SortFields)
+		assert.Equal(t, Strings{"Int1", "Str1"}, b.Builder.ProjectFields())
+
+		cli := &fakeClient{entities: Entities}
+		entities, keys, err := b.GetAll(context.Background(), NewExecutor(cli, Kind4Test))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(keys)) // limited
+		for _, e := range entities {
+			assert.Equal(t, EnumA2, e.EnumA)
+		}
+	}
+
+	{
+		b := For[ComplicatedEntity4Test]("ID", "Subs.S1").Eq("Subs.I1", int64(3)).Starts("Name", "Q").Eq("Strings", "a")
+		assert.NoError(t, b.Err())
+		assert.Equal(t, Conditions{{"Subs.I1", EQ, 3}, {"Name", GTE, "Q"}, {"Name", LT, "R"}, {"Strings", EQ, "a"}}, b.Builder.Conditions)
+	}
+
+	{
+		type sub struct {
+			Code string `datastore:"code"`
+		}
+		type tagged struct {
+			Name string `datastore:"name"`
+			Sub  *sub   `datastore:"sub"`
+		}
+		b := For[tagged]("name").Eq("sub.code", "X")
+		assert.NoError(t, b.Err())
+		assert.Equal(t, Assigners{AssignerFor("Sub.Code", "X")}, b.Builder.Assigns)
+		assert.Error(t, For[tagged]().Eq("Name", "foo").Err())
+	}
+
+	invalids := []*TypedBuilder[Entity4Test]{
+		For[Entity4Test]("Unknown"),
+		For[Entity4Test]().Eq("Int1", "1"),
+		For[Entity4Test]().Eq("Int1", 1.5),
+		For[Entity4Test]().Gt("Str1", 1),
+		For[Entity4Test]().Starts("Int1", "1"),
+		For[Entity4Test]().Desc("Unknown"),
+		For[Entity4Test]().Eq("Int1.Foo", 1),
+	}
+	for _, b := range invalids {
+		assert.Error(t, b.Err())
+		_, _, err := b.GetAll(context.Background(), NewExecutor(&fakeClient{}, Kind4Test))
+		assert.Error(t, err)
+	}
+
+	{ // The first error is kept
+		b := For[Entity4Test]().Eq("Foo", 1).Eq("Bar", 1)
+		assert.Contains(t, b.Err().Error(), "Foo")
+	}
+}
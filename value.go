@@ -0,0 +1,55 @@
+package querybuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	TimeValueType = "time"
+)
+
+// TypedValue returns v and the name of its type to be kept in JSON.
+// The type name is empty for values which JSON can represent by itself.
+func TypedValue(v interface{}) (interface{}, string) {
+	switch v.(type) {
+	case time.Time:
+		return v, TimeValueType
+	default:
+		return v, ""
+	}
+}
+
+func UntypedValue(v interface{}, typ string) (interface{}, error) {
+	switch typ {
+	case "":
+		if m, ok := v.(map[string]interface{}); ok {
+			if _, ok := m["param"]; ok {
+				return ParamFromMap(m)
+			}
+		}
+		return v, nil
+	case TimeValueType:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("Invalid %s value: %v", typ, v)
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	default:
+		return nil, fmt.Errorf("Unknown value type: %s", typ)
+	}
+}
+
+// marshalJSON works like json.Marshal without HTML escaping, which is
+// applied by json.Marshal to the whole output anyway.
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}